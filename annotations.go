@@ -0,0 +1,142 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAnnotationCategory = "note"
+	eventAnnotation           = "annotation"
+)
+
+// annotation is an operator's note about something physical that happened
+// during a trial, such as a refill or a re-tightened fixture.
+type annotation struct {
+	at         time.Time
+	author     string
+	category   string
+	note       string
+	cycleCount int // cycle in progress, or the last one, when the note was made
+}
+
+func (a annotation) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"time":        a.at.Format(time.RFC3339Nano),
+		"author":      a.author,
+		"category":    a.category,
+		"note":        a.note,
+		"cycle_count": a.cycleCount,
+	}
+}
+
+func (a annotation) String() string {
+	return fmt.Sprintf("%s %s [%s]: %s", a.at.Format(time.RFC3339), a.author, a.category, a.note)
+}
+
+func annotationList(list []annotation) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, a := range list {
+		out[i] = a.toMap()
+	}
+	return out
+}
+
+// annotationsFor returns the trial's notes on one cycle.
+func (t *trialState) annotationsFor(cycleCount int) []annotation {
+	var out []annotation
+	for _, a := range t.annotations {
+		if a.cycleCount == cycleCount {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// annotationTags tags a cycle's uploads with the categories of its notes.
+func annotationTags(list []annotation) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	tags := []string{"annotated"}
+	seen := map[string]bool{}
+	for _, a := range list {
+		if !seen[a.category] {
+			seen[a.category] = true
+			tags = append(tags, "annotation:"+a.category)
+		}
+	}
+	return tags
+}
+
+// addUploadLocked remembers an image uploaded for a cycle. Notes attach to
+// the cycle in progress or the last one, so only that cycle's uploads are
+// kept. Callers hold s.mu.
+func (t *trialState) addUploadLocked(cycleCount int, id string) {
+	if cycleCount != t.uploadCycle {
+		t.uploadCycle = cycleCount
+		t.uploadIDs = nil
+	}
+	t.uploadIDs = append(t.uploadIDs, id)
+}
+
+// handleAnnotate records a note against the active trial and the cycle in
+// progress, or the last cycle between cycles. A cycle already in history
+// gets the note added to its record, and images the cycle already uploaded
+// get its tags.
+func (s *kettleCycleTestController) handleAnnotate(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	note, _ := cmd["note"].(string)
+	author, _ := cmd["author"].(string)
+	category, _ := cmd["category"].(string)
+	note, author, category = strings.TrimSpace(note), strings.TrimSpace(author), strings.TrimSpace(category)
+	if note == "" {
+		return nil, fmt.Errorf("annotate requires a 'note'")
+	}
+	if author == "" {
+		return nil, fmt.Errorf("annotate requires an 'author'")
+	}
+	if category == "" {
+		category = defaultAnnotationCategory
+	}
+
+	s.mu.Lock()
+	if s.activeTrial == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("annotate requires an active trial")
+	}
+	a := annotation{
+		at:         time.Now(),
+		author:     author,
+		category:   category,
+		note:       note,
+		cycleCount: s.activeTrial.cycleCount,
+	}
+	s.activeTrial.annotations = append(s.activeTrial.annotations, a)
+	s.history.annotate(s.activeTrial.trialID, a)
+	s.recordEventLocked(eventAnnotation, a.String(), a.toMap())
+	s.logger.Infof("annotation on trial %s cycle %d: %s", s.activeTrial.trialID, a.cycleCount, a)
+
+	result := a.toMap()
+	result["trial_id"] = s.activeTrial.trialID
+	var uploaded []string
+	if s.activeTrial.uploadCycle == a.cycleCount {
+		uploaded = append(uploaded, s.activeTrial.uploadIDs...)
+	}
+	s.mu.Unlock()
+
+	result["tagged_uploads"] = 0
+	if len(uploaded) == 0 || s.dataClient == nil {
+		return result, nil
+	}
+	tags := annotationTags([]annotation{a})
+	if err := s.dataClient.AddTagsToBinaryDataByIDs(ctx, tags, uploaded); err != nil {
+		s.logger.Warnf("failed to tag cycle %d's uploads with the annotation: %v", a.cycleCount, err)
+		s.recordEvent(eventUploadFailed, fmt.Sprintf("tagging uploads with annotation: %v", err), map[string]interface{}{"tags": toInterfaceList(tags)})
+		result["tag_error"] = err.Error()
+		return result, nil
+	}
+	result["tagged_uploads"] = len(uploaded)
+	return result, nil
+}
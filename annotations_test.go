@@ -0,0 +1,170 @@
+package kettlecycletest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"go.viam.com/rdk/app"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+)
+
+// jpegCamera is a camera fake that returns a small JPEG.
+type jpegCamera struct {
+	camera.Camera
+}
+
+func (c *jpegCamera) Name() resource.Name { return camera.Named("camera") }
+
+func (c *jpegCamera) Image(ctx context.Context, mimeType string, extra map[string]interface{}) ([]byte, camera.ImageMetadata, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		return nil, camera.ImageMetadata{}, err
+	}
+	return buf.Bytes(), camera.ImageMetadata{MimeType: mimeType}, nil
+}
+
+func TestAnnotationTags(t *testing.T) {
+	if tags := annotationTags(nil); tags != nil {
+		t.Errorf("expected no tags without notes, got %v", tags)
+	}
+	tags := annotationTags([]annotation{{category: "refill"}, {category: "fixture"}, {category: "refill"}})
+	if strings.Join(tags, ",") != "annotated,annotation:refill,annotation:fixture" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestController_Annotate(t *testing.T) {
+	ctx := context.Background()
+	kctrl := newTestController(t)
+	annotate := func(cmd map[string]interface{}) (map[string]interface{}, error) {
+		cmd["command"] = "annotate"
+		return kctrl.DoCommand(ctx, cmd)
+	}
+
+	if _, err := annotate(map[string]interface{}{"note": "refilled", "author": "sam"}); err == nil {
+		t.Error("expected error without an active trial")
+	}
+
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+	kctrl.mu.Unlock()
+	if _, err := annotate(map[string]interface{}{"author": "sam"}); err == nil {
+		t.Error("expected error without a note")
+	}
+
+	if _, err := kctrl.handleExecuteCycle(ctx); err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	resp, err := annotate(map[string]interface{}{"note": "water refilled", "author": "sam", "category": "refill"})
+	if err != nil {
+		t.Fatalf("annotate failed: %v", err)
+	}
+	if resp["cycle_count"] != 1 || resp["trial_id"] != "test-trial" {
+		t.Errorf("expected the note on cycle 1, got %v", resp)
+	}
+
+	// The recorded cycle picks up the note
+	history, _ := kctrl.DoCommand(ctx, map[string]interface{}{"command": "history"})
+	record := history["cycles"].([]interface{})[0].(map[string]interface{})
+	notes, _ := record["annotations"].([]interface{})
+	if len(notes) != 1 || notes[0].(map[string]interface{})["category"] != "refill" {
+		t.Errorf("expected the note in history, got %v", record["annotations"])
+	}
+
+	state := kctrl.GetState()
+	if state["annotation_count"] != 1 || !strings.Contains(state["last_annotation"].(string), "water refilled") {
+		t.Errorf("expected the note in status, got %v %v", state["annotation_count"], state["last_annotation"])
+	}
+
+	result, err := kctrl.handleStop(ctx)
+	if err != nil {
+		t.Fatalf("handleStop failed: %v", err)
+	}
+	if notes, _ := result["annotations"].([]interface{}); len(notes) != 1 {
+		t.Errorf("expected the note in the trial summary, got %v", result["annotations"])
+	}
+}
+
+// fakeDatasetClient records uploads and tag updates instead of calling the
+// Viam data API.
+type fakeDatasetClient struct {
+	uploads  [][]string // tags of each upload, in order
+	tagged   map[string][]string
+	tagError error
+}
+
+func (f *fakeDatasetClient) UploadImageToDatasets(ctx context.Context, partID string, img image.Image, datasetIDs, tags []string,
+	mimeType app.MimeType, opts *app.FileUploadOptions,
+) (string, error) {
+	f.uploads = append(f.uploads, tags)
+	return fmt.Sprintf("img-%d", len(f.uploads)), nil
+}
+
+func (f *fakeDatasetClient) AddTagsToBinaryDataByIDs(ctx context.Context, tags, binaryDataIDs []string) error {
+	if f.tagError != nil {
+		return f.tagError
+	}
+	for _, id := range binaryDataIDs {
+		f.tagged[id] = append(f.tagged[id], tags...)
+	}
+	return nil
+}
+
+func (f *fakeDatasetClient) ListDatasetsByIDs(ctx context.Context, ids []string) ([]*app.Dataset, error) {
+	return nil, nil
+}
+
+func TestController_AnnotateTagsUploadedImages(t *testing.T) {
+	ctx := context.Background()
+	kctrl := newTestController(t)
+	data := &fakeDatasetClient{tagged: map[string][]string{}}
+	kctrl.camera = &jpegCamera{}
+	kctrl.dataClient = data
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+	kctrl.mu.Unlock()
+
+	if _, err := kctrl.handleExecuteCycle(ctx); err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	// The note comes after cycle 1's image was uploaded, and still reaches it
+	resp, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "annotate", "note": "water refilled", "author": "sam", "category": "refill"})
+	if err != nil {
+		t.Fatalf("annotate failed: %v", err)
+	}
+	if resp["tagged_uploads"] != 1 {
+		t.Errorf("expected cycle 1's image tagged, got %v", resp)
+	}
+	if tags := strings.Join(data.tagged["img-1"], ","); tags != "annotated,annotation:refill" {
+		t.Errorf("expected annotation tags on img-1, got %v", tags)
+	}
+
+	// The next cycle's image is not tagged with a note about cycle 1
+	if _, err := kctrl.handleExecuteCycle(ctx); err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	for _, tag := range data.uploads[1] {
+		if strings.HasPrefix(tag, "annotat") {
+			t.Errorf("expected cycle 2's upload free of cycle 1's note, got %v", data.uploads[1])
+		}
+	}
+
+	data.tagError = errors.New("permission denied")
+	resp, err = kctrl.DoCommand(ctx, map[string]interface{}{"command": "annotate", "note": "fixture re-tightened", "author": "sam"})
+	if err != nil {
+		t.Fatalf("annotate should succeed when tagging fails, got %v", err)
+	}
+	if resp["tagged_uploads"] != 0 || resp["tag_error"] != "permission denied" {
+		t.Errorf("expected the tagging failure reported, got %v", resp)
+	}
+	if notes := kctrl.history.query("test-trial", 0)[1].(map[string]interface{})["annotations"]; len(notes.([]interface{})) != 1 {
+		t.Errorf("expected the note kept on cycle 2 despite the tagging failure, got %v", notes)
+	}
+}
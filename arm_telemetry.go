@@ -0,0 +1,440 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var ArmTelemetry = resource.NewModel("viamdemo", "kettle-cycle-test", "arm-telemetry")
+
+func init() {
+	resource.RegisterComponent(sensor.API, ArmTelemetry,
+		resource.Registration[sensor.Sensor, *ArmTelemetryConfig]{
+			Constructor: newArmTelemetry,
+		},
+	)
+}
+
+// Phases the controller signals to the arm-telemetry sensor.
+const (
+	telemetryPhaseLift    = "lift"     // resting to pour_prep
+	telemetryPhasePutDown = "put_down" // pour_prep back to resting
+)
+
+// ArmTelemetryConfig configures sampling of the arm's joints and end
+// position while the controller has a phase open.
+type ArmTelemetryConfig struct {
+	Arm                      string  `json:"arm"`                                    // REQUIRED: arm to sample
+	SampleRateHz             int     `json:"sample_rate_hz,omitempty"`               // default: 20
+	MotionThresholdDegPerSec float64 `json:"motion_threshold_deg_per_sec,omitempty"` // joint speed counted as moving (default: 1)
+	CaptureTimeout           int     `json:"capture_timeout_ms,omitempty"`           // timeout in ms (default: 30000)
+}
+
+func (cfg *ArmTelemetryConfig) Validate(path string) ([]string, []string, error) {
+	if cfg.Arm == "" {
+		return nil, nil, fmt.Errorf("%s: arm is required", path)
+	}
+	if cfg.SampleRateHz < 0 {
+		return nil, nil, fmt.Errorf("%s: sample_rate_hz must not be negative", path)
+	}
+	if cfg.MotionThresholdDegPerSec < 0 {
+		return nil, nil, fmt.Errorf("%s: motion_threshold_deg_per_sec must not be negative", path)
+	}
+	if cfg.CaptureTimeout < 0 {
+		return nil, nil, fmt.Errorf("%s: capture_timeout_ms must not be negative", path)
+	}
+	return []string{cfg.Arm}, nil, nil
+}
+
+// motionSummary accumulates joint travel, peak joint velocity and time in
+// motion over a run of samples.
+type motionSummary struct {
+	samples      int
+	travelDeg    []float64
+	peakVelocity float64 // deg/s, fastest joint of any sample interval
+	inMotion     time.Duration
+
+	prev   []float64
+	prevAt time.Time
+}
+
+// add folds in one sample of joint positions in degrees.
+func (m *motionSummary) add(jointsDeg []float64, at time.Time, thresholdDegPerSec float64) {
+	m.samples++
+	if m.travelDeg == nil {
+		m.travelDeg = make([]float64, len(jointsDeg))
+	}
+	if m.prev != nil && len(m.prev) == len(jointsDeg) && len(m.travelDeg) == len(jointsDeg) {
+		if dt := at.Sub(m.prevAt).Seconds(); dt > 0 {
+			var fastest float64
+			for i, deg := range jointsDeg {
+				delta := math.Abs(deg - m.prev[i])
+				m.travelDeg[i] += delta
+				fastest = math.Max(fastest, delta/dt)
+			}
+			m.peakVelocity = math.Max(m.peakVelocity, fastest)
+			if fastest > thresholdDegPerSec {
+				m.inMotion += at.Sub(m.prevAt)
+			}
+		}
+	}
+	m.prev = append(m.prev[:0], jointsDeg...)
+	m.prevAt = at
+}
+
+// pause drops the previous sample so the gap between phases is not counted.
+func (m *motionSummary) pause() {
+	m.prev = nil
+}
+
+func (m *motionSummary) toMap() map[string]interface{} {
+	var total float64
+	for _, deg := range m.travelDeg {
+		total += deg
+	}
+	return map[string]interface{}{
+		"sample_count":                    m.samples,
+		"joint_travel_deg":                floatsToInterface(m.travelDeg),
+		"total_joint_travel_deg":          total,
+		"peak_joint_velocity_deg_per_sec": m.peakVelocity,
+		"time_in_motion_ms":               m.inMotion.Milliseconds(),
+	}
+}
+
+// floatsToInterface converts a list of numbers for a reading or DoCommand result.
+func floatsToInterface(list []float64) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, v := range list {
+		out[i] = v
+	}
+	return out
+}
+
+type armTelemetry struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+
+	arm                arm.Arm
+	sampleRateHz       int
+	motionThresholdDeg float64
+	captureTimeout     time.Duration
+
+	cancelFunc func()
+	wg         sync.WaitGroup
+
+	mu           sync.Mutex
+	capturing    bool
+	generation   int // bumped per capture so a late sample is not credited to the next one
+	timeoutTimer *time.Timer
+	fault        string // last read error, cleared by a successful read
+
+	// Trial metadata passed via start_capture
+	trialID    string
+	cycleCount int
+	metadata   captureMetadata
+	phase      string
+
+	// Summaries of the open (or last) phase and of every phase of the cycle
+	phaseSummary *motionSummary
+	cycleSummary *motionSummary
+	cycleKey     string // trial and cycle the cycle summary belongs to
+
+	lastJointsDeg []float64
+	lastPosition  map[string]interface{}
+}
+
+func newArmTelemetry(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	conf, err := resource.NativeConfig[*ArmTelemetryConfig](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := arm.FromProvider(deps, conf.Arm)
+	if err != nil {
+		return nil, fmt.Errorf("getting arm: %w", err)
+	}
+
+	sampleRate := conf.SampleRateHz
+	if sampleRate <= 0 {
+		sampleRate = 20
+	}
+	threshold := conf.MotionThresholdDegPerSec
+	if threshold <= 0 {
+		threshold = 1
+	}
+	captureTimeout := conf.CaptureTimeout
+	if captureTimeout <= 0 {
+		captureTimeout = 30000
+	}
+
+	at := &armTelemetry{
+		name:               rawConf.ResourceName(),
+		logger:             logger,
+		arm:                a,
+		sampleRateHz:       sampleRate,
+		motionThresholdDeg: threshold,
+		captureTimeout:     time.Duration(captureTimeout) * time.Millisecond,
+		phaseSummary:       &motionSummary{},
+		cycleSummary:       &motionSummary{},
+	}
+	at.startSampling()
+	logger.Infof("arm-telemetry sampling %q at %d Hz during phases", conf.Arm, sampleRate)
+	return at, nil
+}
+
+func (at *armTelemetry) Name() resource.Name {
+	return at.name
+}
+
+// startSampling launches the sampling loop under a context that Close cancels.
+func (at *armTelemetry) startSampling() {
+	ctx, cancel := context.WithCancel(context.Background())
+	at.cancelFunc = cancel
+	at.wg.Add(1)
+	go func() {
+		defer at.wg.Done()
+		ticker := time.NewTicker(time.Second / time.Duration(at.sampleRateHz))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				at.sample(ctx)
+			}
+		}
+	}()
+}
+
+// sample reads the arm once while a phase is open.
+func (at *armTelemetry) sample(ctx context.Context) {
+	at.mu.Lock()
+	capturing := at.capturing
+	generation := at.generation
+	at.mu.Unlock()
+	if !capturing {
+		return
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	inputs, err := at.arm.JointPositions(readCtx, nil)
+	if err != nil {
+		at.setFault(fmt.Sprintf("reading joint positions: %v", err))
+		return
+	}
+	var position map[string]interface{}
+	if pose, err := at.arm.EndPosition(readCtx, nil); err == nil {
+		point := pose.Point()
+		position = map[string]interface{}{"x": point.X, "y": point.Y, "z": point.Z}
+	}
+	now := time.Now()
+
+	jointsDeg := make([]float64, len(inputs))
+	for i, rad := range inputs {
+		jointsDeg[i] = rad * 180 / math.Pi
+	}
+
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	if !at.capturing || at.generation != generation {
+		return
+	}
+	at.fault = ""
+	at.lastJointsDeg = jointsDeg
+	if position != nil {
+		at.lastPosition = position
+	}
+	at.phaseSummary.add(jointsDeg, now, at.motionThresholdDeg)
+	at.cycleSummary.add(jointsDeg, now, at.motionThresholdDeg)
+}
+
+func (at *armTelemetry) setFault(fault string) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	if at.fault != fault {
+		at.logger.Warnf("arm-telemetry: %s", fault)
+	}
+	at.fault = fault
+}
+
+// Readings reports the latest pose and the running summary of the current
+// cycle. should_sync is true only while a phase of a trial is open.
+func (at *armTelemetry) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	state := "idle"
+	if at.capturing {
+		state = "capturing"
+	}
+	result := map[string]interface{}{
+		"trial_id":      at.trialID,
+		"cycle_count":   at.cycleCount,
+		"should_sync":   at.trialID != "",
+		"capture_state": state,
+		"phase":         at.phase,
+		"fault":         at.fault,
+	}
+	at.metadata.addTo(result)
+	if at.lastJointsDeg != nil {
+		result["joint_positions_deg"] = floatsToInterface(at.lastJointsDeg)
+	}
+	if at.lastPosition != nil {
+		result["end_position_mm"] = at.lastPosition
+	}
+	for k, v := range at.cycleSummary.toMap() {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (at *armTelemetry) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	command, ok := cmd["command"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'command' field")
+	}
+
+	switch command {
+	case "start_capture":
+		return at.handleStartCapture(cmd)
+	case "end_capture":
+		return at.handleEndCapture()
+	default:
+		return nil, fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// handleStartCapture opens a phase. Phases with the same trial_id and
+// cycle_count add to one cycle summary; a new cycle, or "new_cycle": true,
+// starts a fresh one.
+func (at *armTelemetry) handleStartCapture(cmd map[string]interface{}) (map[string]interface{}, error) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	if at.capturing {
+		return nil, fmt.Errorf("capture already in progress (phase: %s)", at.phase)
+	}
+
+	at.trialID, _ = cmd["trial_id"].(string)
+	at.cycleCount = 0
+	if cycleCount, ok := toFloat64(cmd["cycle_count"]); ok {
+		at.cycleCount = int(cycleCount)
+	}
+	at.metadata = parseCaptureMetadata(cmd)
+	at.phase, _ = cmd["phase"].(string)
+
+	key := fmt.Sprintf("%s/%d", at.trialID, at.cycleCount)
+	if newCycle, _ := cmd["new_cycle"].(bool); newCycle || key != at.cycleKey {
+		at.cycleSummary = &motionSummary{}
+		at.cycleKey = key
+	}
+	at.cycleSummary.pause()
+	at.phaseSummary = &motionSummary{}
+	at.generation++
+	at.capturing = true
+
+	generation := at.generation
+	at.timeoutTimer = time.AfterFunc(at.captureTimeout, func() {
+		at.mu.Lock()
+		defer at.mu.Unlock()
+		if at.capturing && at.generation == generation {
+			at.logger.Errorf("capture timeout: end_capture not called within %v", at.captureTimeout)
+			at.capturing = false
+			at.trialID = ""
+			at.cycleCount = 0
+			at.metadata = nil
+		}
+	})
+
+	at.logger.Debugf("arm telemetry capture started (phase %q, cycle %d)", at.phase, at.cycleCount)
+	return map[string]interface{}{"status": "capturing"}, nil
+}
+
+// handleEndCapture closes the phase and returns its summary alongside the
+// cycle's summary so far.
+func (at *armTelemetry) handleEndCapture() (map[string]interface{}, error) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	if !at.capturing {
+		return nil, fmt.Errorf("no capture in progress")
+	}
+	if at.timeoutTimer != nil {
+		at.timeoutTimer.Stop()
+		at.timeoutTimer = nil
+	}
+	at.capturing = false
+
+	result := map[string]interface{}{
+		"trial_id":    at.trialID,
+		"cycle_count": at.cycleCount,
+		"phase":       at.phase,
+		"summary":     at.phaseSummary.toMap(),
+		"cycle":       at.cycleSummary.toMap(),
+	}
+	at.metadata.addTo(result)
+
+	// Clear trial metadata so should_sync stops between phases
+	at.trialID = ""
+	at.cycleCount = 0
+	at.metadata = nil
+	return result, nil
+}
+
+func (at *armTelemetry) Close(context.Context) error {
+	at.mu.Lock()
+	if at.timeoutTimer != nil {
+		at.timeoutTimer.Stop()
+	}
+	at.mu.Unlock()
+
+	at.cancelFunc()
+	at.wg.Wait()
+	return nil
+}
+
+// startTelemetryPhase tells the arm-telemetry sensor a move is starting.
+// Telemetry is advisory, so a failure only warns.
+func (s *kettleCycleTestController) startTelemetryPhase(ctx context.Context, phase, trialID string, cycleCount int, metadata trialMetadata) {
+	if s.telemetry == nil {
+		return
+	}
+	cmd := map[string]interface{}{
+		"command":     "start_capture",
+		"trial_id":    trialID,
+		"cycle_count": cycleCount,
+		"phase":       phase,
+		"new_cycle":   phase == telemetryPhaseLift,
+	}
+	metadata.addCaptureFields(cmd)
+	if _, err := s.telemetry.DoCommand(ctx, cmd); err != nil {
+		s.logger.Warnf("failed to start arm telemetry for %s: %v", phase, err)
+	}
+}
+
+// endTelemetryPhase closes the open phase and records the cycle's motion so
+// far in the result.
+func (s *kettleCycleTestController) endTelemetryPhase(ctx context.Context, result map[string]interface{}) {
+	if s.telemetry == nil {
+		return
+	}
+	resp, err := s.telemetry.DoCommand(ctx, map[string]interface{}{"command": "end_capture"})
+	if err != nil {
+		s.logger.Warnf("failed to end arm telemetry: %v", err)
+		return
+	}
+	if cycle, ok := resp["cycle"].(map[string]interface{}); ok {
+		result["arm_telemetry"] = cycle
+	}
+}
@@ -0,0 +1,150 @@
+package kettlecycletest
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestMotionSummary(t *testing.T) {
+	var m motionSummary
+	start := time.Now()
+	m.add([]float64{0, 0}, start, 1)
+	m.add([]float64{10, -5}, start.Add(time.Second), 1)
+	m.add([]float64{10, -5.5}, start.Add(2*time.Second), 1)
+
+	summary := m.toMap()
+	if summary["total_joint_travel_deg"] != 15.5 || summary["peak_joint_velocity_deg_per_sec"] != 10.0 {
+		t.Errorf("expected 15.5 deg travel at 10 deg/s peak, got %v", summary)
+	}
+	if summary["time_in_motion_ms"] != int64(1000) {
+		t.Errorf("expected only the first second in motion, got %v", summary["time_in_motion_ms"])
+	}
+
+	// The gap after pause is not travel
+	m.pause()
+	m.add([]float64{20, -5.5}, start.Add(3*time.Second), 1)
+	if m.toMap()["total_joint_travel_deg"] != 15.5 || m.samples != 4 {
+		t.Errorf("expected pause to skip the gap, got %v", m.toMap())
+	}
+}
+
+func TestArmTelemetry(t *testing.T) {
+	if _, _, err := (&ArmTelemetryConfig{}).Validate("test"); err == nil {
+		t.Error("expected error without arm")
+	}
+
+	var mu sync.Mutex
+	joint := 0.0
+	testArm := inject.NewArm("arm")
+	testArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		joint += 0.01 // radians per sample, well above the motion threshold
+		return []float64{joint, 0}, nil
+	}
+	testArm.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 200}), nil
+	}
+	rawConf := resource.Config{
+		Name:                "telemetry",
+		API:                 sensor.API,
+		Model:               ArmTelemetry,
+		ConvertedAttributes: &ArmTelemetryConfig{Arm: "arm", SampleRateHz: 100},
+	}
+	deps := resource.Dependencies{resource.NewName(arm.API, "arm"): testArm}
+	s, err := newArmTelemetry(context.Background(), deps, rawConf, logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("newArmTelemetry failed: %v", err)
+	}
+	defer s.Close(context.Background())
+	ctx := context.Background()
+
+	readings, _ := s.Readings(ctx, nil)
+	if readings["should_sync"] != false || readings["capture_state"] != "idle" {
+		t.Errorf("expected idle reading outside a phase, got %v", readings)
+	}
+
+	for _, phase := range []string{telemetryPhaseLift, telemetryPhasePutDown} {
+		if _, err := s.DoCommand(ctx, map[string]interface{}{
+			"command": "start_capture", "trial_id": "trial-1", "cycle_count": 3, "phase": phase,
+			"specimen_id": "SN-1042", "operator": "sam",
+		}); err != nil {
+			t.Fatalf("start_capture failed: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		readings, _ = s.Readings(ctx, nil)
+		if readings["should_sync"] != true || readings["trial_id"] != "trial-1" || readings["phase"] != phase {
+			t.Errorf("expected syncing reading during %s, got %v", phase, readings)
+		}
+		if readings["specimen_id"] != "SN-1042" || readings["operator"] != "sam" {
+			t.Errorf("expected specimen and operator in readings during %s, got %v", phase, readings)
+		}
+		if _, ok := readings["end_position_mm"]; !ok {
+			t.Errorf("expected end position in readings, got %v", readings)
+		}
+
+		resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "end_capture"})
+		if err != nil {
+			t.Fatalf("end_capture failed: %v", err)
+		}
+		phaseTravel := resp["summary"].(map[string]interface{})["total_joint_travel_deg"].(float64)
+		cycleTravel := resp["cycle"].(map[string]interface{})["total_joint_travel_deg"].(float64)
+		if phase == telemetryPhasePutDown && cycleTravel <= phaseTravel {
+			t.Errorf("expected the cycle to include both phases, got phase %v cycle %v", phaseTravel, cycleTravel)
+		}
+	}
+
+	readings, _ = s.Readings(ctx, nil)
+	if readings["should_sync"] != false || readings["trial_id"] != "" || readings["operator"] != "" {
+		t.Errorf("expected trial metadata cleared after end_capture, got %v", readings)
+	}
+	if v := readings["peak_joint_velocity_deg_per_sec"].(float64); v < 1 || math.IsInf(v, 0) {
+		t.Errorf("expected the cycle's peak velocity in readings, got %v", v)
+	}
+	if _, err := s.DoCommand(ctx, map[string]interface{}{"command": "end_capture"}); err == nil {
+		t.Error("expected error ending with no capture in progress")
+	}
+}
+
+func TestController_ArmTelemetry(t *testing.T) {
+	kctrl := newTestController(t)
+	var phases []string
+	telemetry := inject.NewSensor("telemetry")
+	telemetry.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "start_capture" {
+			phases = append(phases, cmd["phase"].(string))
+			return map[string]interface{}{"status": "capturing"}, nil
+		}
+		return map[string]interface{}{"cycle": map[string]interface{}{"total_joint_travel_deg": 90.0 * float64(len(phases))}}, nil
+	}
+	kctrl.telemetry = telemetry
+
+	result, err := kctrl.handleExecuteCycle(context.Background())
+	if err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	if len(phases) != 2 || phases[0] != telemetryPhaseLift || phases[1] != telemetryPhasePutDown {
+		t.Errorf("expected lift then put_down phases, got %v", phases)
+	}
+	summary := result["arm_telemetry"].(map[string]interface{})
+	if summary["total_joint_travel_deg"] != 180.0 {
+		t.Errorf("expected the summary after put_down, got %v", summary)
+	}
+	history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+	record := history["cycles"].([]interface{})[0].(map[string]interface{})
+	if _, ok := record["arm_telemetry"]; !ok {
+		t.Errorf("expected arm telemetry in history, got %v", record)
+	}
+}
@@ -11,8 +11,10 @@ import (
 
 func main() {
 	module.ModularMain(
-		resource.APIModel{generic.API, kettlecycletest.Controller},
-		resource.APIModel{sensor.API, kettlecycletest.TrialSensor},
-		resource.APIModel{sensor.API, kettlecycletest.ForceSensor},
+		resource.APIModel{API: generic.API, Model: kettlecycletest.Controller},
+		resource.APIModel{API: sensor.API, Model: kettlecycletest.TrialSensor},
+		resource.APIModel{API: sensor.API, Model: kettlecycletest.ForceSensor},
+		resource.APIModel{API: sensor.API, Model: kettlecycletest.MockLoadCell},
+		resource.APIModel{API: sensor.API, Model: kettlecycletest.ArmTelemetry},
 	)
 }
@@ -0,0 +1,259 @@
+package kettlecycletest
+
+import (
+	"fmt"
+	"math"
+)
+
+// driftProfilePoints is the length every force profile is resampled to, so
+// put-downs of different durations can be compared point by point.
+const driftProfilePoints = 50
+
+// DriftConfig enables force profile drift detection against a baseline
+// learned from the first cycles of each trial.
+type DriftConfig struct {
+	BaselineCycles int     `json:"baseline_cycles,omitempty"` // cycles averaged into the baseline (default: 10)
+	Threshold      float64 `json:"threshold,omitempty"`       // drift score that counts as drifted (default: 0.15)
+	AlertAfter     int     `json:"alert_after,omitempty"`     // consecutive drifted cycles before alerting (default: 3)
+
+	// Per-point envelope of the baseline: mean ± envelope_sigma standard
+	// deviations, at least envelope_floor of the baseline's peak wide
+	EnvelopeSigma float64 `json:"envelope_sigma,omitempty"` // default: 3
+	EnvelopeFloor float64 `json:"envelope_floor,omitempty"` // default: 0.05
+	MaxOutside    float64 `json:"max_outside,omitempty"`    // fraction of points outside the envelope that counts as drifted (default: 0.2)
+}
+
+func (cfg *DriftConfig) validate() error {
+	if cfg.BaselineCycles < 0 {
+		return fmt.Errorf("baseline_cycles must not be negative")
+	}
+	if cfg.Threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if cfg.AlertAfter < 0 {
+		return fmt.Errorf("alert_after must not be negative")
+	}
+	if cfg.EnvelopeSigma < 0 {
+		return fmt.Errorf("envelope_sigma must not be negative")
+	}
+	if cfg.EnvelopeFloor < 0 {
+		return fmt.Errorf("envelope_floor must not be negative")
+	}
+	if cfg.MaxOutside < 0 || cfg.MaxOutside > 1 {
+		return fmt.Errorf("max_outside must be between 0 and 1")
+	}
+	return nil
+}
+
+func (cfg DriftConfig) withDefaults() DriftConfig {
+	if cfg.BaselineCycles == 0 {
+		cfg.BaselineCycles = 10
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 0.15
+	}
+	if cfg.AlertAfter == 0 {
+		cfg.AlertAfter = 3
+	}
+	if cfg.EnvelopeSigma == 0 {
+		cfg.EnvelopeSigma = 3
+	}
+	if cfg.EnvelopeFloor == 0 {
+		cfg.EnvelopeFloor = 0.05
+	}
+	if cfg.MaxOutside == 0 {
+		cfg.MaxOutside = 0.2
+	}
+	return cfg
+}
+
+// driftMonitor scores each put-down profile against the trial's baseline.
+// The score is the RMS difference from the baseline relative to the
+// baseline's RMS: 0 is identical, 0.1 is a 10% deviation. A cycle has also
+// drifted when too many of its points fall outside the baseline envelope,
+// which catches a change of shape that barely moves the RMS.
+type driftMonitor struct {
+	cfg DriftConfig
+
+	learning     [][]float64 // resampled profiles collected for the baseline
+	baseline     []float64   // mean curve; nil until baselineCycles profiles are collected
+	lower, upper []float64   // envelope around the mean
+	lastScore    float64
+	lastOutside  float64 // fraction of the last profile's points outside the envelope
+	scored       bool
+	consecutive  int // drifted cycles in a row
+	alerting     bool
+}
+
+func newDriftMonitor(cfg DriftConfig) *driftMonitor {
+	return &driftMonitor{cfg: cfg.withDefaults()}
+}
+
+// observe adds one cycle's profile. Once a baseline exists it returns the
+// drift score, the fraction of points outside the envelope, and whether this
+// cycle started an alert.
+func (m *driftMonitor) observe(samples []float64) (score, outside float64, scored, newAlert bool) {
+	if len(samples) == 0 {
+		return 0, 0, false, false
+	}
+	profile := resampleProfile(samples, driftProfilePoints)
+
+	if m.baseline == nil {
+		m.learning = append(m.learning, profile)
+		if len(m.learning) >= m.cfg.BaselineCycles {
+			m.baseline, m.lower, m.upper = profileEnvelope(m.learning, m.cfg.EnvelopeSigma, m.cfg.EnvelopeFloor)
+			m.learning = nil
+		}
+		return 0, 0, false, false
+	}
+
+	score = profileDistance(profile, m.baseline)
+	outside = outsideFraction(profile, m.lower, m.upper)
+	m.lastScore = score
+	m.lastOutside = outside
+	m.scored = true
+	if score > m.cfg.Threshold || outside > m.cfg.MaxOutside {
+		m.consecutive++
+	} else {
+		m.consecutive = 0
+		m.alerting = false
+	}
+	if m.consecutive >= m.cfg.AlertAfter && !m.alerting {
+		m.alerting = true
+		newAlert = true
+	}
+	return score, outside, true, newAlert
+}
+
+// state returns the drift fields for GetState. Only scalars, since state is
+// synced with every reading; the envelope is served by drift_baseline.
+func (m *driftMonitor) state() map[string]interface{} {
+	var score, outside interface{}
+	if m.scored {
+		score = m.lastScore
+		outside = m.lastOutside
+	}
+	return map[string]interface{}{
+		"drift_score":            score,
+		"drift_outside_fraction": outside,
+		"drift_baseline_ready":   m.baseline != nil,
+		"drift_consecutive_over": m.consecutive,
+		"drift_alert":            m.alerting,
+	}
+}
+
+// envelope returns the baseline curve and its envelope, nil until learned.
+func (m *driftMonitor) envelope() map[string]interface{} {
+	if m.baseline == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"mean":  floatsToInterface(m.baseline),
+		"lower": floatsToInterface(m.lower),
+		"upper": floatsToInterface(m.upper),
+	}
+}
+
+// resampleProfile linearly interpolates samples onto n evenly spaced points.
+func resampleProfile(samples []float64, n int) []float64 {
+	out := make([]float64, n)
+	if len(samples) == 1 {
+		for i := range out {
+			out[i] = samples[0]
+		}
+		return out
+	}
+	step := float64(len(samples)-1) / float64(n-1)
+	for i := range out {
+		pos := float64(i) * step
+		lo := int(pos)
+		if lo >= len(samples)-1 {
+			out[i] = samples[len(samples)-1]
+			continue
+		}
+		frac := pos - float64(lo)
+		out[i] = samples[lo]*(1-frac) + samples[lo+1]*frac
+	}
+	return out
+}
+
+func averageProfiles(profiles [][]float64) []float64 {
+	avg := make([]float64, len(profiles[0]))
+	for _, p := range profiles {
+		for i, v := range p {
+			avg[i] += v
+		}
+	}
+	for i := range avg {
+		avg[i] /= float64(len(profiles))
+	}
+	return avg
+}
+
+// profileEnvelope returns the mean curve and, per point, mean ± sigma
+// standard deviations. The half-width is at least floor times the mean's
+// peak, so a baseline of near-identical cycles still leaves room for noise.
+func profileEnvelope(profiles [][]float64, sigma, floor float64) (mean, lower, upper []float64) {
+	mean = averageProfiles(profiles)
+	var peak float64
+	for _, v := range mean {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	lower = make([]float64, len(mean))
+	upper = make([]float64, len(mean))
+	for i := range mean {
+		var sq float64
+		for _, p := range profiles {
+			d := p[i] - mean[i]
+			sq += d * d
+		}
+		half := math.Max(sigma*math.Sqrt(sq/float64(len(profiles))), floor*peak)
+		lower[i] = mean[i] - half
+		upper[i] = mean[i] + half
+	}
+	return mean, lower, upper
+}
+
+// outsideFraction is the share of points outside [lower, upper].
+func outsideFraction(profile, lower, upper []float64) float64 {
+	var n int
+	for i, v := range profile {
+		if v < lower[i] || v > upper[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(profile))
+}
+
+// profileDistance is the RMS difference relative to the baseline's RMS
+// (absolute for an all-zero baseline).
+func profileDistance(profile, baseline []float64) float64 {
+	var diff, ref float64
+	for i := range baseline {
+		d := profile[i] - baseline[i]
+		diff += d * d
+		ref += baseline[i] * baseline[i]
+	}
+	if ref == 0 {
+		return math.Sqrt(diff / float64(len(baseline)))
+	}
+	return math.Sqrt(diff / ref)
+}
+
+// floatList reads a list of numbers from a DoCommand result.
+func floatList(v interface{}) []float64 {
+	switch list := v.(type) {
+	case []float64:
+		return list
+	case []interface{}:
+		out := make([]float64, 0, len(list))
+		for _, item := range list {
+			if f, ok := toFloat64(item); ok {
+				out = append(out, f)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
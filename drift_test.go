@@ -0,0 +1,220 @@
+package kettlecycletest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestResampleProfile(t *testing.T) {
+	got := resampleProfile([]float64{0, 10, 20}, 5)
+	want := []float64{0, 5, 10, 15, 20}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("resampleProfile = %v, want %v", got, want)
+		}
+	}
+	if got := resampleProfile([]float64{7}, 3); got[2] != 7 {
+		t.Errorf("single sample should fill the profile, got %v", got)
+	}
+}
+
+func TestDriftMonitor(t *testing.T) {
+	normal := []float64{50, 100, 150, 200, 200, 200}
+	light := []float64{40, 80, 120, 160, 160, 160} // 20% lighter
+
+	m := newDriftMonitor(DriftConfig{BaselineCycles: 2, Threshold: 0.1, AlertAfter: 2})
+	for i := 0; i < 2; i++ {
+		if _, _, scored, _ := m.observe(normal); scored {
+			t.Fatal("expected no score while learning the baseline")
+		}
+	}
+	if m.state()["drift_baseline_ready"] != true {
+		t.Fatal("expected baseline after baseline_cycles")
+	}
+
+	score, _, scored, alert := m.observe(normal)
+	if !scored || score != 0 || alert {
+		t.Errorf("identical profile: score=%v scored=%v alert=%v", score, scored, alert)
+	}
+
+	score, _, _, alert = m.observe(light)
+	if math.Abs(score-0.2) > 1e-9 || alert {
+		t.Errorf("first drifted cycle: score=%v alert=%v, want 0.2 without alert", score, alert)
+	}
+	if _, _, _, alert = m.observe(light); !alert {
+		t.Error("expected alert after alert_after consecutive drifted cycles")
+	}
+	if _, _, _, alert = m.observe(light); alert {
+		t.Error("expected a single alert per drift episode")
+	}
+	if m.state()["drift_alert"] != true {
+		t.Error("expected drift_alert while still drifted")
+	}
+
+	m.observe(normal)
+	if m.state()["drift_alert"] != false || m.state()["drift_consecutive_over"] != 0 {
+		t.Errorf("expected alert to clear after a normal cycle, got %v", m.state())
+	}
+}
+
+func TestDriftMonitor_Envelope(t *testing.T) {
+	normal := []float64{50, 100, 150, 200, 200, 200}
+	scaled := func(f float64) []float64 {
+		out := make([]float64, len(normal))
+		for i, v := range normal {
+			out[i] = v * f
+		}
+		return out
+	}
+
+	m := newDriftMonitor(DriftConfig{BaselineCycles: 3, Threshold: 0.1, AlertAfter: 1, EnvelopeFloor: 0.01})
+	for _, f := range []float64{0.99, 1, 1.01} {
+		m.observe(scaled(f))
+	}
+	envelope := m.envelope()
+	if envelope == nil || len(envelope["upper"].([]interface{})) != driftProfilePoints {
+		t.Fatalf("expected the envelope once the baseline is learned, got %v", envelope)
+	}
+	if _, ok := m.state()["drift_envelope"]; ok {
+		t.Error("expected the envelope kept out of state")
+	}
+
+	// Within the baseline's spread: inside the envelope
+	score, outside, _, alert := m.observe(scaled(1.005))
+	if outside != 0 || alert {
+		t.Errorf("expected a profile within the spread inside the envelope, got outside=%v score=%v", outside, score)
+	}
+
+	// 5% heavier stays near the mean (score under threshold) but leaves the
+	// tight envelope everywhere
+	score, outside, _, alert = m.observe(scaled(1.05))
+	if score > 0.1 {
+		t.Fatalf("expected a score under threshold, got %v", score)
+	}
+	if outside < 0.9 || !alert {
+		t.Errorf("expected most points outside the envelope and an alert, got outside=%v alert=%v", outside, alert)
+	}
+	if m.state()["drift_outside_fraction"] != outside {
+		t.Errorf("expected drift_outside_fraction in state, got %v", m.state()["drift_outside_fraction"])
+	}
+}
+
+func TestController_Drift(t *testing.T) {
+	t.Run("config requires force_sensor", func(t *testing.T) {
+		cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", Drift: &DriftConfig{}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for drift without force_sensor")
+		}
+		cfg.ForceSensor = "force"
+		if _, _, err := cfg.Validate("test"); err != nil {
+			t.Errorf("expected valid config, got %v", err)
+		}
+	})
+
+	t.Run("scores cycles and reports drift in state", func(t *testing.T) {
+		peak := 200.0
+		fs := inject.NewSensor("force")
+		fs.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			if cmd["command"] != "end_capture" {
+				return map[string]interface{}{"status": "waiting"}, nil
+			}
+			return map[string]interface{}{"samples": []interface{}{peak / 2, peak, peak}}, nil
+		}
+
+		kctrl := newTestController(t)
+		kctrl.forceSensor = fs
+		kctrl.cfg.Drift = &DriftConfig{BaselineCycles: 1, AlertAfter: 1}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{
+			trialID: "test-trial",
+			stopCh:  make(chan struct{}),
+			drift:   newDriftMonitor(*kctrl.cfg.Drift),
+		}
+		kctrl.mu.Unlock()
+
+		result, _ := kctrl.handleExecuteCycle(context.Background())
+		if _, ok := result["drift_score"]; ok {
+			t.Error("expected no drift_score while learning the baseline")
+		}
+
+		peak = 100
+		result, _ = kctrl.handleExecuteCycle(context.Background())
+		if score, _ := result["drift_score"].(float64); score < 0.15 {
+			t.Errorf("expected drift_score above threshold, got %v", result["drift_score"])
+		}
+		state := kctrl.GetState()
+		if state["drift_alert"] != true || state["drift_score"] == nil {
+			t.Errorf("expected drift alert in state, got %v", state)
+		}
+
+		baseline, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "drift_baseline"})
+		if err != nil {
+			t.Fatalf("drift_baseline failed: %v", err)
+		}
+		if baseline["drift_baseline_ready"] != true || len(baseline["mean"].([]interface{})) != driftProfilePoints {
+			t.Errorf("expected the learned baseline, got %v", baseline)
+		}
+	})
+
+	t.Run("drift_baseline requires an active trial with drift", func(t *testing.T) {
+		kctrl := newTestController(t)
+		if _, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "drift_baseline"}); err == nil {
+			t.Error("expected an error without an active trial")
+		}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+		if _, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "drift_baseline"}); err == nil {
+			t.Error("expected an error without drift configured")
+		}
+	})
+	t.Run("manual cycles stay out of the trial's baseline and count", func(t *testing.T) {
+		peak := 100.0
+		fs := inject.NewSensor("force")
+		fs.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			if cmd["command"] != "end_capture" {
+				return map[string]interface{}{"status": "waiting"}, nil
+			}
+			return map[string]interface{}{"samples": []interface{}{peak / 2, peak, peak}}, nil
+		}
+
+		kctrl := newTestController(t)
+		kctrl.forceSensor = fs
+		kctrl.cfg.Drift = &DriftConfig{BaselineCycles: 1, AlertAfter: 1}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{
+			trialID: "test-trial",
+			stopCh:  make(chan struct{}),
+			drift:   newDriftMonitor(*kctrl.cfg.Drift),
+		}
+		kctrl.mu.Unlock()
+
+		result, err := kctrl.handleManualCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleManualCycle failed: %v", err)
+		}
+		if result["manual"] != true || result["drift_score"] != nil {
+			t.Errorf("expected an unscored manual result, got %v", result)
+		}
+		if state := kctrl.GetState(); state["cycle_count"] != 0 || state["drift_baseline_ready"] != false {
+			t.Errorf("expected the manual cycle kept out of the trial, got count %v baseline ready %v", state["cycle_count"], state["drift_baseline_ready"])
+		}
+
+		// The baseline is learned from the trial's own first cycle
+		peak = 200
+		kctrl.handleExecuteCycle(context.Background())
+		result, _ = kctrl.handleExecuteCycle(context.Background())
+		if score, _ := result["drift_score"].(float64); score != 0 {
+			t.Errorf("expected no drift against a baseline from trial cycles, got %v", result["drift_score"])
+		}
+		if count := kctrl.GetState()["cycle_count"]; count != 2 {
+			t.Errorf("expected 2 trial cycles counted, got %v", count)
+		}
+		if records := kctrl.history.query("test-trial", 0); len(records) != 2 {
+			t.Errorf("expected only trial cycles under the trial in history, got %d", len(records))
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package kettlecycletest
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultEventLogSize = 1000
+	maxEventWait        = 30 * time.Second
+)
+
+// Event kinds recorded besides notifications, which are recorded under
+// their own kind (trial_faulted, drift_alert, ...).
+const (
+	eventTrialStarted   = "trial_started"
+	eventTrialStopped   = "trial_stopped"
+	eventCycleCompleted = "cycle_completed"
+	eventCycleFaulted   = "cycle_faulted"
+	eventPhaseError     = "phase_error"
+	eventForceAnomaly   = "force_anomaly"
+	eventUploadFailed   = "upload_failed"
+	eventVisionResult   = "vision_result" // vision service classifications, not preflight probes
+)
+
+// event is one entry in the controller's event log.
+type event struct {
+	seq        uint64
+	at         time.Time
+	kind       string
+	trialID    string
+	specimenID string // the trial's specimen, when start named one
+	cycleCount int
+	message    string
+	data       map[string]interface{}
+}
+
+func (e event) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"seq":         e.seq,
+		"time":        e.at.Format(time.RFC3339Nano),
+		"kind":        e.kind,
+		"trial_id":    e.trialID,
+		"cycle_count": e.cycleCount,
+		"message":     e.message,
+	}
+	if e.specimenID != "" {
+		m["specimen_id"] = e.specimenID
+	}
+	if e.data != nil {
+		m["data"] = e.data
+	}
+	return m
+}
+
+// eventLog keeps the newest events with monotonically increasing sequence
+// numbers. Callers hold the controller's mutex; waiters block on changed,
+// which is closed and replaced on every append.
+type eventLog struct {
+	size    int
+	events  []event
+	lastSeq uint64
+	changed chan struct{}
+}
+
+func newEventLog(size int) *eventLog {
+	if size <= 0 {
+		size = defaultEventLogSize
+	}
+	return &eventLog{size: size, changed: make(chan struct{})}
+}
+
+func (l *eventLog) add(e event) {
+	l.lastSeq++
+	e.seq = l.lastSeq
+	if len(l.events) >= l.size {
+		l.events = append(l.events[:0], l.events[1:]...)
+	}
+	l.events = append(l.events, e)
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// since returns up to limit events after seq (all when limit <= 0), and
+// whether events after seq were already dropped from the log.
+func (l *eventLog) since(seq uint64, limit int) ([]interface{}, bool) {
+	missed := len(l.events) > 0 && l.events[0].seq > seq+1
+	out := []interface{}{}
+	for _, e := range l.events {
+		if e.seq <= seq {
+			continue
+		}
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		out = append(out, e.toMap())
+	}
+	return out, missed
+}
+
+// recordEventLocked appends an event tagged with the active trial, if any.
+// Callers hold s.mu.
+func (s *kettleCycleTestController) recordEventLocked(kind, message string, data map[string]interface{}) {
+	e := event{at: time.Now(), kind: kind, message: message, data: data}
+	if trial := s.cycleTrialLocked(); trial != nil {
+		e.trialID = trial.trialID
+		e.specimenID = trial.metadata.fields[metaSpecimenID]
+		e.cycleCount = trial.cycleCount
+	}
+	s.events.add(e)
+}
+
+func (s *kettleCycleTestController) recordEvent(kind, message string, data map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordEventLocked(kind, message, data)
+}
+
+// handleEvents returns events after "since" (default 0). With "wait_ms" and
+// nothing new, it waits up to that long (at most 30 s) for the next event.
+func (s *kettleCycleTestController) handleEvents(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	var since uint64
+	if v, ok := toFloat64(cmd["since"]); ok && v > 0 {
+		since = uint64(v)
+	}
+	limit := 0
+	if v, ok := toFloat64(cmd["limit"]); ok {
+		limit = int(v)
+	}
+	var wait time.Duration
+	if v, ok := toFloat64(cmd["wait_ms"]); ok && v > 0 {
+		wait = min(time.Duration(v)*time.Millisecond, maxEventWait)
+	}
+
+	deadline := time.After(wait)
+	for {
+		s.mu.Lock()
+		events, missed := s.events.since(since, limit)
+		lastSeq := s.events.lastSeq
+		changed := s.events.changed
+		s.mu.Unlock()
+
+		if len(events) > 0 || wait == 0 {
+			return map[string]interface{}{"events": events, "last_seq": lastSeq, "missed": missed}, nil
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			wait = 0
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventLog(t *testing.T) {
+	l := newEventLog(3)
+	for i := 0; i < 5; i++ {
+		l.add(event{kind: eventCycleCompleted, at: time.Now()})
+	}
+
+	events, missed := l.since(0, 0)
+	if len(events) != 3 || !missed {
+		t.Errorf("expected the newest 3 events and missed, got %d %v", len(events), missed)
+	}
+	if first := events[0].(map[string]interface{}); first["seq"] != uint64(3) {
+		t.Errorf("expected sequence to keep counting past dropped events, got %v", first["seq"])
+	}
+	events, missed = l.since(4, 0)
+	if len(events) != 1 || missed {
+		t.Errorf("expected one event after seq 4, got %d %v", len(events), missed)
+	}
+	if events, _ = l.since(2, 1); len(events) != 1 {
+		t.Errorf("expected limit to cap the events, got %d", len(events))
+	}
+}
+
+func TestController_Events(t *testing.T) {
+	kctrl := newTestController(t)
+	ctx := context.Background()
+
+	if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "execute_cycle"}); err != nil {
+		t.Fatalf("execute_cycle failed: %v", err)
+	}
+	resp, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "events"})
+	if err != nil {
+		t.Fatalf("events failed: %v", err)
+	}
+	events := resp["events"].([]interface{})
+	if len(events) != 1 || events[0].(map[string]interface{})["kind"] != eventCycleCompleted {
+		t.Fatalf("expected a cycle_completed event, got %v", events)
+	}
+	lastSeq := resp["last_seq"]
+
+	// Nothing new: the long-poll times out empty
+	start := time.Now()
+	resp, _ = kctrl.DoCommand(ctx, map[string]interface{}{"command": "events", "since": lastSeq, "wait_ms": 50})
+	if len(resp["events"].([]interface{})) != 0 || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("expected an empty reply after the wait, got %v", resp)
+	}
+
+	// A notification wakes a waiting poll
+	done := make(chan map[string]interface{}, 1)
+	go func() {
+		resp, _ := kctrl.DoCommand(ctx, map[string]interface{}{"command": "events", "since": lastSeq, "wait_ms": 5000})
+		done <- resp
+	}()
+	time.Sleep(20 * time.Millisecond)
+	kctrl.mu.Lock()
+	kctrl.notifyLocked("drift_alert", "profile drifted")
+	kctrl.mu.Unlock()
+
+	select {
+	case resp := <-done:
+		event := resp["events"].([]interface{})[0].(map[string]interface{})
+		if event["kind"] != "drift_alert" || event["message"] != "profile drifted" {
+			t.Errorf("expected the notification as an event, got %v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the long-poll to return on the new event")
+	}
+}
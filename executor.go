@@ -0,0 +1,177 @@
+package kettlecycletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Policies for manual arm commands sent while a trial is cycling.
+const (
+	manualReject = "reject" // refuse with an error naming the owner (default)
+	manualQueue  = "queue"  // wait behind the in-flight cycle
+)
+
+// errDropped is returned to queued operations removed by abort.
+var errDropped = errors.New("dropped from the arm queue by abort")
+
+// armExecutor serializes operations that move the arm. Operations run one
+// at a time in arrival order; priority operations go to the front.
+type armExecutor struct {
+	mu      sync.Mutex
+	owner   string // operation holding the arm, "" when free
+	waiters []*armWaiter
+}
+
+type armWaiter struct {
+	owner string
+	ready chan struct{}
+	err   error // set before ready is closed when the wait was dropped
+}
+
+// acquire waits until the arm is free and marks owner as holding it.
+func (e *armExecutor) acquire(ctx context.Context, owner string, priority bool) error {
+	e.mu.Lock()
+	if e.owner == "" && len(e.waiters) == 0 {
+		e.owner = owner
+		e.mu.Unlock()
+		return nil
+	}
+	w := &armWaiter{owner: owner, ready: make(chan struct{})}
+	if priority {
+		e.waiters = append([]*armWaiter{w}, e.waiters...)
+	} else {
+		e.waiters = append(e.waiters, w)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for i, queued := range e.waiters {
+			if queued == w {
+				e.waiters = append(e.waiters[:i], e.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// Handed the arm as ctx ended; pass it on
+		if w.err == nil {
+			e.releaseLocked()
+		}
+		return ctx.Err()
+	}
+}
+
+func (e *armExecutor) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.releaseLocked()
+}
+
+func (e *armExecutor) releaseLocked() {
+	if len(e.waiters) == 0 {
+		e.owner = ""
+		return
+	}
+	next := e.waiters[0]
+	e.waiters = e.waiters[1:]
+	e.owner = next.owner
+	close(next.ready)
+}
+
+// dropQueued fails every waiting operation; the current owner keeps the arm.
+func (e *armExecutor) dropQueued() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, w := range e.waiters {
+		w.err = errDropped
+		close(w.ready)
+	}
+	n := len(e.waiters)
+	e.waiters = nil
+	return n
+}
+
+func (e *armExecutor) state() (string, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.owner, len(e.waiters)
+}
+
+// looping reports whether the trial's cycle loop is still running.
+func (t *trialState) looping() bool {
+	if t.loopDone == nil {
+		return false
+	}
+	select {
+	case <-t.loopDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// acquireForTrial waits for the arm on behalf of a trial's cycle loop,
+// giving up when the trial is stopped.
+func (s *kettleCycleTestController) acquireForTrial(trialID string, stopCh chan struct{}) error {
+	ctx, cancel := context.WithCancel(s.cancelCtx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return s.executor.acquire(ctx, "trial "+trialID, false)
+}
+
+// checkManual refuses a manual arm command while a trial is cycling,
+// unless manual_during_trial queues it.
+func (s *kettleCycleTestController) checkManual(command string) error {
+	s.mu.Lock()
+	var cycling string
+	if s.activeTrial != nil && s.activeTrial.looping() {
+		cycling = s.activeTrial.trialID
+	}
+	s.mu.Unlock()
+
+	if cycling != "" && s.cfg.ManualDuringTrial != manualQueue {
+		current, _ := s.executor.state()
+		return fmt.Errorf("%s rejected: trial %s is running (arm owner: %s); stop the trial first", command, cycling, current)
+	}
+	return nil
+}
+
+// runManual runs a manual arm command through the executor. While a trial
+// is cycling it is refused or queued according to manual_during_trial.
+func (s *kettleCycleTestController) runManual(ctx context.Context, command string, fn func(context.Context) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if err := s.checkManual(command); err != nil {
+		return nil, err
+	}
+	if err := s.executor.acquire(ctx, "manual "+command, false); err != nil {
+		return nil, fmt.Errorf("%s waiting for the arm: %w", command, err)
+	}
+	defer s.executor.release()
+	return fn(ctx)
+}
+
+func validateManualDuringTrial(policy string) error {
+	switch policy {
+	case "", manualReject, manualQueue:
+		return nil
+	default:
+		return fmt.Errorf("must be %q or %q", manualReject, manualQueue)
+	}
+}
+
+// addExecutorState reports who holds the arm and how many operations wait.
+func (s *kettleCycleTestController) addExecutorState(state map[string]interface{}) {
+	owner, depth := s.executor.state()
+	state["executor_owner"] = owner
+	state["executor_queue_depth"] = depth
+}
@@ -0,0 +1,148 @@
+package kettlecycletest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestArmExecutor(t *testing.T) {
+	var e armExecutor
+	ctx := context.Background()
+	if err := e.acquire(ctx, "first", false); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	order := make(chan string, 3)
+	queue := func(owner string, priority bool) chan error {
+		_, before := e.state()
+		done := make(chan error, 1)
+		go func() {
+			err := e.acquire(ctx, owner, priority)
+			if err == nil {
+				order <- owner
+				e.release()
+			}
+			done <- err
+		}()
+		for _, depth := e.state(); depth == before; _, depth = e.state() {
+			time.Sleep(time.Millisecond)
+		}
+		return done
+	}
+	queue("second", false)
+	queue("urgent", true)
+	if owner, depth := e.state(); owner != "first" || depth != 2 {
+		t.Fatalf("expected first holding with 2 queued, got %q %d", owner, depth)
+	}
+
+	e.release()
+	if got := <-order; got != "urgent" {
+		t.Errorf("expected the priority waiter first, got %s", got)
+	}
+	if got := <-order; got != "second" {
+		t.Errorf("expected second next, got %s", got)
+	}
+
+	// Abort drops whoever is waiting
+	for owner, _ := e.state(); owner != ""; owner, _ = e.state() {
+		time.Sleep(time.Millisecond)
+	}
+	e.acquire(ctx, "cycle", false)
+	dropped := queue("manual", false)
+	if n := e.dropQueued(); n != 1 {
+		t.Errorf("expected one dropped waiter, got %d", n)
+	}
+	if err := <-dropped; !errors.Is(err, errDropped) {
+		t.Errorf("expected errDropped, got %v", err)
+	}
+}
+
+func TestController_ManualDuringTrial(t *testing.T) {
+	newCyclingController := func(t *testing.T, policy string) (*kettleCycleTestController, chan struct{}) {
+		kctrl := newTestController(t)
+		kctrl.cfg.ManualDuringTrial = policy
+		unblock := make(chan struct{})
+		lifting := make(chan struct{}, 1)
+		pourPrep := inject.NewSwitch("pour-prep")
+		pourPrep.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) { return 2, nil }
+		pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			select {
+			case lifting <- struct{}{}:
+			default:
+			}
+			select {
+			case <-unblock:
+			case <-ctx.Done():
+			}
+			return nil
+		}
+		kctrl.pourPrep = pourPrep
+		if _, err := kctrl.handleStart(context.Background(), nil); err != nil {
+			t.Fatalf("handleStart failed: %v", err)
+		}
+		<-lifting
+		return kctrl, unblock
+	}
+
+	t.Run("manual cycle is rejected by default", func(t *testing.T) {
+		kctrl, unblock := newCyclingController(t, "")
+		_, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "execute_cycle"})
+		if err == nil || !strings.Contains(err.Error(), "rejected") {
+			t.Errorf("expected rejection, got %v", err)
+		}
+		state := kctrl.GetState()
+		if owner, _ := state["executor_owner"].(string); !strings.HasPrefix(owner, "trial ") {
+			t.Errorf("expected the trial to own the arm, got %v", state["executor_owner"])
+		}
+		close(unblock)
+		kctrl.handleStop(context.Background())
+		if kctrl.GetState()["executor_owner"] != "" {
+			t.Error("expected the arm free after stop")
+		}
+	})
+
+	t.Run("manual cycle queues behind the in-flight cycle", func(t *testing.T) {
+		kctrl, unblock := newCyclingController(t, manualQueue)
+		done := make(chan error, 1)
+		go func() {
+			_, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "execute_cycle"})
+			done <- err
+		}()
+
+		deadline := time.Now().Add(time.Second)
+		for kctrl.GetState()["executor_queue_depth"] != 1 {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the manual cycle queued")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		close(unblock)
+		if err := <-done; err != nil {
+			t.Errorf("expected the queued cycle to run, got %v", err)
+		}
+		result, _ := kctrl.handleStop(context.Background())
+
+		// The manual cycle is in history on its own, outside the trial's count
+		var trialCycles, manualCycles int
+		for _, r := range kctrl.history.query("", 0) {
+			record := r.(map[string]interface{})
+			switch {
+			case record["manual"] == true && record["trial_id"] == "":
+				manualCycles++
+			case record["manual"] == false && record["trial_id"] == result["trial_id"]:
+				trialCycles++
+			}
+		}
+		if manualCycles != 1 {
+			t.Errorf("expected one manual cycle in history, got %d", manualCycles)
+		}
+		if count := result["cycle_count"].(int); count != trialCycles {
+			t.Errorf("expected the trial to count only its own %d cycles, got %d", trialCycles, count)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.viam.com/rdk/components/sensor"
@@ -22,76 +23,121 @@ func init() {
 }
 
 type ForceSensorConfig struct {
-	LoadCell       string  `json:"load_cell"`                    // REQUIRED: name of load cell sensor
-	UseMockCurve   bool    `json:"use_mock_curve,omitempty"`     // optional: use mock force curve instead of hardware
-	ForceKey       string  `json:"force_key,omitempty"`
-	SampleRateHz   int     `json:"sample_rate_hz,omitempty"`
-	BufferSize     int     `json:"buffer_size,omitempty"`
-	ZeroThreshold  float64 `json:"zero_threshold,omitempty"`     // readings below this are "zero" (default: 5.0)
-	CaptureTimeout int     `json:"capture_timeout_ms,omitempty"` // timeout in ms (default: 10000)
+	LoadCell       string   `json:"load_cell"`               // REQUIRED unless load_cells, replay_file or use_mock_curve is set: name of load cell sensor (a mock-load-cell for simulation)
+	ForceKey       string   `json:"force_key,omitempty"`     // dotted path ("channels.0") or JSON pointer ("/channels/0")
+	ForceKeys      []string `json:"force_keys,omitempty"`    // several paths combined via force_combine
+	ForceCombine   string   `json:"force_combine,omitempty"` // "sum" (default) or "difference" (first minus the rest)
+	SampleRateHz   int      `json:"sample_rate_hz,omitempty"`
+	BufferSize     int      `json:"buffer_size,omitempty"`
+	ZeroThreshold  float64  `json:"zero_threshold,omitempty"`     // readings below this are "zero" (default: 5.0)
+	CaptureTimeout int      `json:"capture_timeout_ms,omitempty"` // timeout in ms (default: 10000)
+
+	// Deprecated: read an internal mock-load-cell instead of load_cell
+	UseMockCurve bool               `json:"use_mock_curve,omitempty"`
+	MockProfile  *MockProfileConfig `json:"mock_profile,omitempty"` // profile of the use_mock_curve cell
+
+	// Multiple load cells under the platform (alternative to load_cell)
+	LoadCells          []LoadCellConfig `json:"load_cells,omitempty"`
+	ExpectedCenter     *PlatformPoint   `json:"expected_center,omitempty"`      // default: centroid of load_cells
+	OffCenterTolerance float64          `json:"off_center_tolerance,omitempty"` // alarm distance, 0 disables
+
+	// Replay recorded profiles (.csv or .jsonl) instead of reading hardware
+	ReplayFile    string `json:"replay_file,omitempty"`
+	ReplayLoop    bool   `json:"replay_loop,omitempty"`     // start over after the last profile
+	ReplayByCycle bool   `json:"replay_by_cycle,omitempty"` // pick the profile matching cycle_count
+
+	// Put-down judgement reported as impact_flags by end_capture
+	Impact *ImpactConfig `json:"impact,omitempty"`
 }
 
 func (cfg *ForceSensorConfig) Validate(path string) ([]string, []string, error) {
+	if cfg.Impact != nil {
+		if err := cfg.Impact.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: impact: %w", path, err)
+		}
+	}
+	if cfg.MockProfile != nil {
+		if !cfg.UseMockCurve {
+			return nil, nil, fmt.Errorf("%s: mock_profile requires use_mock_curve; a mock-load-cell sets its own profile", path)
+		}
+		if err := cfg.MockProfile.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: mock_profile: %w", path, err)
+		}
+	}
+	if cfg.ReplayFile != "" {
+		if cfg.LoadCell != "" || len(cfg.LoadCells) > 0 || cfg.UseMockCurve {
+			return nil, nil, fmt.Errorf("%s: replay_file cannot be combined with load_cell, load_cells, or use_mock_curve", path)
+		}
+		return nil, nil, nil
+	}
+	if cfg.ReplayLoop || cfg.ReplayByCycle {
+		return nil, nil, fmt.Errorf("%s: replay_loop and replay_by_cycle require replay_file", path)
+	}
+	if cfg.UseMockCurve && cfg.LoadCell == "" && len(cfg.LoadCells) == 0 {
+		return nil, nil, nil
+	}
+	if len(cfg.LoadCells) > 0 {
+		return cfg.validateLoadCells(path)
+	}
 	if cfg.LoadCell == "" {
 		return nil, nil, fmt.Errorf("%s: load_cell is required", path)
 	}
+	if cfg.OffCenterTolerance != 0 || cfg.ExpectedCenter != nil {
+		return nil, nil, fmt.Errorf("%s: off_center_tolerance and expected_center require load_cells", path)
+	}
+	if _, err := cfg.readingPaths(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
 	return []string{cfg.LoadCell}, nil, nil
 }
 
-// forceReader abstracts force reading for mock vs hardware implementations
-type forceReader interface {
-	ReadForce(ctx context.Context) (float64, error)
-}
-
-// mockForceReader simulates realistic force profile: zeros while lifted, ramp on contact
-type mockForceReader struct {
-	mu           sync.Mutex
-	inContact    bool
-	contactCount int
-}
-
-func newMockForceReader() *mockForceReader {
-	return &mockForceReader{}
-}
-
-func (m *mockForceReader) ReadForce(ctx context.Context) (float64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if !m.inContact {
-		// Kettle is lifted - return near-zero with small noise
-		return 0.5, nil
+func (cfg *ForceSensorConfig) validateLoadCells(path string) ([]string, []string, error) {
+	if cfg.LoadCell != "" {
+		return nil, nil, fmt.Errorf("%s: load_cell and load_cells are mutually exclusive", path)
+	}
+	if cfg.ForceKey != "" || len(cfg.ForceKeys) > 0 || cfg.ForceCombine != "" {
+		return nil, nil, fmt.Errorf("%s: set force_key/force_keys/force_combine per entry in load_cells", path)
+	}
+	if cfg.OffCenterTolerance < 0 {
+		return nil, nil, fmt.Errorf("%s: off_center_tolerance must not be negative", path)
 	}
 
-	// Kettle is in contact - simulate force ramp
-	m.contactCount++
-	// Ramp from 50 to 200 over 50 samples, then hold
-	if m.contactCount < 50 {
-		return 50.0 + float64(m.contactCount)*3.0, nil
+	deps := make([]string, 0, len(cfg.LoadCells))
+	seen := make(map[string]bool)
+	for i := range cfg.LoadCells {
+		cell := &cfg.LoadCells[i]
+		if err := cell.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: load_cells[%d]: %w", path, i, err)
+		}
+		if seen[cell.Name] {
+			return nil, nil, fmt.Errorf("%s: load_cells[%d]: duplicate load cell %q", path, i, cell.Name)
+		}
+		seen[cell.Name] = true
+		deps = append(deps, cell.Name)
 	}
-	return 200.0, nil
+	return deps, nil, nil
 }
 
-func (m *mockForceReader) SetContact(inContact bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.inContact = inContact
-	if inContact {
-		m.contactCount = 0
-	}
+// readingPaths parses force_key/force_keys into paths, defaulting to "value".
+func (cfg *ForceSensorConfig) readingPaths() ([]readingPath, error) {
+	return parseForcePaths(cfg.ForceKey, cfg.ForceKeys, cfg.ForceCombine)
+}
+
+// forceReader abstracts force reading for mock vs hardware implementations
+type forceReader interface {
+	ReadForce(ctx context.Context) (float64, error)
 }
 
-// sensorForceReader wraps a Viam sensor component to read force values
+// sensorForceReader wraps a Viam sensor component to read force values.
+// Each path is extracted from the readings and the results are combined.
 type sensorForceReader struct {
-	sensor   sensor.Sensor
-	forceKey string
+	sensor  sensor.Sensor
+	paths   []readingPath
+	combine string
 }
 
-func newSensorForceReader(s sensor.Sensor, forceKey string) *sensorForceReader {
-	if forceKey == "" {
-		forceKey = "value"
-	}
-	return &sensorForceReader{sensor: s, forceKey: forceKey}
+func newSensorForceReader(s sensor.Sensor, paths []readingPath, combine string) *sensorForceReader {
+	return &sensorForceReader{sensor: s, paths: paths, combine: combine}
 }
 
 func (r *sensorForceReader) ReadForce(ctx context.Context) (float64, error) {
@@ -100,51 +146,73 @@ func (r *sensorForceReader) ReadForce(ctx context.Context) (float64, error) {
 		return 0, err
 	}
 
-	val, ok := readings[r.forceKey]
-	if !ok {
-		return 0, fmt.Errorf("sensor readings missing %q key", r.forceKey)
-	}
-
-	switch v := val.(type) {
-	case float64:
-		return v, nil
-	case int:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	default:
-		return 0, fmt.Errorf("sensor reading %q is not numeric: %T", r.forceKey, val)
+	values := make([]float64, 0, len(r.paths))
+	for _, p := range r.paths {
+		v, err := p.extract(readings)
+		if err != nil {
+			return 0, fmt.Errorf("sensor readings: %w", err)
+		}
+		values = append(values, v)
 	}
+	return combineReadings(values, r.combine), nil
 }
 
 type captureState int
 
 const (
-	captureIdle captureState = iota
-	captureWaiting  // waiting for first non-zero reading
-	captureActive   // actively capturing samples
+	captureIdle    captureState = iota
+	captureWaiting              // waiting for first non-zero reading
+	captureActive               // actively capturing samples
 )
 
 type forceSensor struct {
 	resource.AlwaysRebuild
 
-	name   resource.Name
-	logger logging.Logger
-	reader forceReader
+	name     resource.Name
+	logger   logging.Logger
+	reader   forceReader
+	replay   *replayForceReader // the reader when replaying a file, told when captures start and end
+	mock     *mockLoadCell      // the internal cell read with use_mock_curve, put down for each capture
+	loadCell sensor.Sensor      // the single wrapped cell, which set_mock_profile is forwarded to
 
 	sampleRateHz   int
 	bufferSize     int
 	zeroThreshold  float64
 	captureTimeout time.Duration
+	impact         ImpactConfig
+
+	// Multi-cell platform geometry (cells is nil for a single load cell)
+	cells              []LoadCellConfig
+	expectedCenter     PlatformPoint
+	offCenterTolerance float64
+
+	// Sampling goroutine lifecycle; Close cancels and waits
+	cancelCtx   context.Context
+	cancelFunc  func()
+	wg          sync.WaitGroup
+	readTimeout time.Duration // per-read limit derived from the sample period
+
+	// Reads run on a dedicated worker so a stuck load cell can't block the loop.
+	// Owned by the sampling goroutine.
+	readReq     chan struct{}
+	readRes     chan sampleResult
+	readPending bool
+
+	// The sampling goroutine is the ring's only writer and touches no mutex
+	// per tick; state transitions are atomic.
+	ring         *sampleRing
+	state        atomic.Int32 // captureState
+	droppedTicks atomic.Int64 // ticks missed this capture because a read overran
+	faulted      atomic.Bool  // mirrors fault != "" so a healthy tick skips the lock
 
 	mu           sync.Mutex
-	samples      []float64
-	state        captureState
+	fault        string // last sensor fault (stuck read), cleared by a successful read
 	timeoutTimer *time.Timer
 
 	// Trial metadata passed via start_capture
 	trialID    string
 	cycleCount int
+	metadata   captureMetadata
 }
 
 func newForceSensor(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
@@ -174,31 +242,88 @@ func newForceSensor(ctx context.Context, deps resource.Dependencies, rawConf res
 	}
 
 	var reader forceReader
+	var replay *replayForceReader
+	var mock *mockLoadCell
+	var loadCell sensor.Sensor
+	var cells []LoadCellConfig
 	if conf.UseMockCurve {
-		reader = newMockForceReader()
-		logger.Infof("force-sensor using mock curve (use_mock_curve=true)")
+		var profile MockProfileConfig
+		if conf.MockProfile != nil {
+			profile = *conf.MockProfile
+		}
+		mock = newSimulatedLoadCell(sensor.Named(rawConf.Name+"-mock-curve"), profile, sampleRate, logger)
+		paths, err := parseForcePaths("", nil, "")
+		if err != nil {
+			return nil, err
+		}
+		reader = newSensorForceReader(mock, paths, "")
+		loadCell = mock
+		logger.Warnf("force-sensor use_mock_curve is deprecated; reading an internal mock-load-cell. Configure a mock-load-cell sensor as load_cell instead")
+	} else if conf.ReplayFile != "" {
+		profiles, err := loadReplayProfiles(conf.ReplayFile)
+		if err != nil {
+			return nil, err
+		}
+		replay = newReplayForceReader(profiles, conf.ReplayLoop, conf.ReplayByCycle)
+		reader = replay
+		logger.Infof("force-sensor replaying %d recorded profiles from %s", len(profiles), conf.ReplayFile)
+	} else if len(conf.LoadCells) > 0 {
+		multi := &multiCellForceReader{}
+		for _, cell := range conf.LoadCells {
+			loadCellSensor, err := sensor.FromProvider(deps, cell.Name)
+			if err != nil {
+				return nil, fmt.Errorf("getting load cell %q: %w", cell.Name, err)
+			}
+			paths, err := parseForcePaths(cell.ForceKey, cell.ForceKeys, cell.ForceCombine)
+			if err != nil {
+				return nil, fmt.Errorf("load cell %q: %w", cell.Name, err)
+			}
+			multi.names = append(multi.names, cell.Name)
+			multi.readers = append(multi.readers, newSensorForceReader(loadCellSensor, paths, cell.ForceCombine))
+		}
+		reader = multi
+		cells = conf.LoadCells
+		logger.Infof("force-sensor wrapping %d load cells: %v", len(cells), multi.names)
 	} else {
 		loadCellSensor, err := sensor.FromProvider(deps, conf.LoadCell)
 		if err != nil {
 			return nil, fmt.Errorf("getting load_cell sensor: %w", err)
 		}
-		reader = newSensorForceReader(loadCellSensor, conf.ForceKey)
-		logger.Infof("force-sensor wrapping load cell %q (key: %q)", conf.LoadCell, conf.ForceKey)
+		paths, err := conf.readingPaths()
+		if err != nil {
+			return nil, err
+		}
+		reader = newSensorForceReader(loadCellSensor, paths, conf.ForceCombine)
+		loadCell = loadCellSensor
+		logger.Infof("force-sensor wrapping load cell %q (paths: %v, combine: %q)", conf.LoadCell, paths, conf.ForceCombine)
 	}
 
 	fs := &forceSensor{
 		name:           rawConf.ResourceName(),
 		logger:         logger,
 		reader:         reader,
+		replay:         replay,
+		mock:           mock,
+		loadCell:       loadCell,
 		sampleRateHz:   sampleRate,
 		bufferSize:     bufferSize,
 		zeroThreshold:  zeroThreshold,
 		captureTimeout: time.Duration(captureTimeout) * time.Millisecond,
-		samples:        make([]float64, 0, bufferSize),
-		state:          captureIdle,
+		ring:           newSampleRing(bufferSize, len(cells)),
+	}
+	if conf.Impact != nil {
+		fs.impact = *conf.Impact
+	}
+	if len(cells) > 0 {
+		fs.cells = cells
+		fs.expectedCenter = cellCentroid(cells)
+		if conf.ExpectedCenter != nil {
+			fs.expectedCenter = *conf.ExpectedCenter
+		}
+		fs.offCenterTolerance = conf.OffCenterTolerance
 	}
 
-	go fs.samplingLoop()
+	fs.startSampling()
 
 	return fs, nil
 }
@@ -208,12 +333,14 @@ func (fs *forceSensor) Name() resource.Name {
 }
 
 func (fs *forceSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	samplesCopy, cellSamplesCopy := fs.ring.snapshot()
+	state := fs.captureState()
+
 	fs.mu.Lock()
-	samplesCopy := make([]float64, len(fs.samples))
-	copy(samplesCopy, fs.samples)
-	state := fs.state
+	fault := fs.fault
 	trialID := fs.trialID
 	cycleCount := fs.cycleCount
+	metadata := fs.metadata
 	fs.mu.Unlock()
 
 	samplesInterface := make([]interface{}, len(samplesCopy))
@@ -239,7 +366,10 @@ func (fs *forceSensor) Readings(ctx context.Context, extra map[string]interface{
 		"samples":       samplesInterface,
 		"sample_count":  len(samplesCopy),
 		"capture_state": stateStr,
+		"fault":         fault,
+		"dropped_ticks": fs.droppedTicks.Load(),
 	}
+	metadata.addTo(result)
 
 	if len(samplesCopy) > 0 {
 		max := samplesCopy[0]
@@ -249,51 +379,235 @@ func (fs *forceSensor) Readings(ctx context.Context, extra map[string]interface{
 			}
 		}
 		result["max_force"] = max
+		result["total_force"] = samplesCopy[len(samplesCopy)-1]
+	}
+
+	if len(fs.cells) > 0 {
+		fs.addCellReadings(result, cellSamplesCopy)
 	}
 
 	return result, nil
 }
 
-func (fs *forceSensor) samplingLoop() {
-	ticker := time.NewTicker(time.Second / time.Duration(fs.sampleRateHz))
-	defer ticker.Stop()
+// addCellReadings adds per-cell profiles and the center of pressure over time.
+func (fs *forceSensor) addCellReadings(result map[string]interface{}, cellSamples [][]float64) {
+	profiles := make(map[string]interface{}, len(fs.cells))
+	for i, cell := range fs.cells {
+		profile := make([]interface{}, len(cellSamples))
+		for j, forces := range cellSamples {
+			profile[j] = forces[i]
+		}
+		profiles[cell.Name] = profile
+	}
+	result["cell_samples"] = profiles
+
+	// Center of pressure is undefined (nil) while nothing rests on the platform
+	copX := make([]interface{}, len(cellSamples))
+	copY := make([]interface{}, len(cellSamples))
+	for i, forces := range cellSamples {
+		if cop, ok := centerOfPressure(fs.cells, forces); ok {
+			copX[i] = cop.X
+			copY[i] = cop.Y
+		}
+	}
+	result["cop_x"] = copX
+	result["cop_y"] = copY
+
+	for k, v := range fs.landingResult(cellSamples) {
+		result[k] = v
+	}
+}
+
+// landingResult summarizes where the kettle landed relative to the expected center.
+func (fs *forceSensor) landingResult(cellSamples [][]float64) map[string]interface{} {
+	summary, ok := summarizeLanding(fs.cells, fs.expectedCenter, fs.offCenterTolerance, cellSamples)
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"center_of_pressure": summary.peakCoP.toMap(),
+		"landing_offset":     summary.offset,
+		"max_landing_offset": summary.maxOffset,
+		"off_center":         summary.offCenter,
+	}
+}
+
+// readSample reads the total force and, for multi-cell readers, each cell's force.
+func (fs *forceSensor) readSample(ctx context.Context) (float64, []float64, error) {
+	if cr, ok := fs.reader.(cellReader); ok && len(fs.cells) > 0 {
+		forces, err := cr.ReadCells(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		return sumForces(forces), forces, nil
+	}
+	force, err := fs.reader.ReadForce(ctx)
+	return force, nil, err
+}
+
+const (
+	// readTimeoutPeriods is how many sample periods a single read may take
+	readTimeoutPeriods = 5
+	minReadTimeout     = 10 * time.Millisecond
+)
 
+type sampleResult struct {
+	force float64
+	cells []float64
+	err   error
+}
+
+// startSampling launches the sampling loop under a context that Close cancels.
+func (fs *forceSensor) startSampling() {
+	period := time.Second / time.Duration(fs.sampleRateHz)
+	if fs.readTimeout <= 0 {
+		fs.readTimeout = max(readTimeoutPeriods*period, minReadTimeout)
+	}
+	fs.cancelCtx, fs.cancelFunc = context.WithCancel(context.Background())
+	fs.readReq = make(chan struct{}, 1)
+	fs.readRes = make(chan sampleResult, 1)
+
+	go fs.runReadWorker(fs.cancelCtx)
+
+	fs.wg.Add(1)
+	go func() {
+		defer fs.wg.Done()
+		fs.runSamplingLoop(fs.cancelCtx)
+	}()
+}
+
+// runReadWorker performs reads on request. It is not tracked by wg: a read
+// stuck in hardware must not block Close, and it exits once the read returns.
+func (fs *forceSensor) runReadWorker(ctx context.Context) {
 	for {
 		select {
-		case <-ticker.C:
-			fs.mu.Lock()
-			currentState := fs.state
-			fs.mu.Unlock()
+		case <-ctx.Done():
+			return
+		case <-fs.readReq:
+			readCtx, cancel := context.WithTimeout(ctx, fs.readTimeout)
+			force, cells, err := fs.readSample(readCtx)
+			cancel()
+			fs.readRes <- sampleResult{force: force, cells: cells, err: err}
+		}
+	}
+}
 
-			if currentState == captureIdle {
+// readWithTimeout requests one read and waits at most readTimeout for it.
+// A read that outlives its timeout is abandoned and reported as a fault;
+// no new read starts until it returns, and its stale value is discarded.
+func (fs *forceSensor) readWithTimeout(ctx context.Context, timer *time.Timer) (float64, []float64, error) {
+	if fs.readPending {
+		select {
+		case <-fs.readRes:
+			fs.readPending = false
+		default:
+			return 0, nil, fmt.Errorf("load cell read stuck for more than %v", fs.readTimeout)
+		}
+	}
+
+	fs.readReq <- struct{}{}
+	fs.readPending = true
+	timer.Reset(fs.readTimeout)
+	select {
+	case res := <-fs.readRes:
+		fs.readPending = false
+		if !timer.Stop() {
+			<-timer.C
+		}
+		return res.force, res.cells, res.err
+	case <-timer.C:
+		return 0, nil, fmt.Errorf("load cell read stuck for more than %v", fs.readTimeout)
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	}
+}
+
+func (fs *forceSensor) captureState() captureState {
+	return captureState(fs.state.Load())
+}
+
+func (fs *forceSensor) setCaptureState(state captureState) {
+	fs.state.Store(int32(state))
+}
+
+func (fs *forceSensor) runSamplingLoop(ctx context.Context) {
+	period := time.Second / time.Duration(fs.sampleRateHz)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	var lastTick time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-ticker.C:
+			if fs.captureState() == captureIdle {
+				lastTick = time.Time{}
 				continue
 			}
+			fs.countDroppedTicks(lastTick, tick, period)
+			lastTick = tick
 
-			force, err := fs.reader.ReadForce(context.Background())
+			force, cellForces, err := fs.readWithTimeout(ctx, timer)
 			if err != nil {
-				fs.logger.Warnf("failed to read force: %v", err)
+				if ctx.Err() != nil {
+					return
+				}
+				if fs.readPending {
+					fs.setFault(err.Error())
+				} else {
+					fs.logger.Warnf("failed to read force: %v", err)
+				}
 				continue
 			}
+			if fs.faulted.Load() {
+				fs.setFault("")
+			}
 
-			fs.mu.Lock()
-			if fs.state == captureWaiting && force >= fs.zeroThreshold {
+			if force >= fs.zeroThreshold &&
+				fs.state.CompareAndSwap(int32(captureWaiting), int32(captureActive)) {
 				// First non-zero reading - start capturing
-				fs.state = captureActive
-				fs.samples = fs.samples[:0]
+				fs.ring.reset()
 				fs.logger.Infof("force capture started (first reading: %.2f)", force)
 			}
 
-			if fs.state == captureActive {
-				if len(fs.samples) >= fs.bufferSize {
-					fs.samples = fs.samples[1:]
-				}
-				fs.samples = append(fs.samples, force)
+			if fs.captureState() == captureActive {
+				fs.ring.push(force, cellForces)
 			}
-			fs.mu.Unlock()
 		}
 	}
 }
 
+// countDroppedTicks adds the ticks the ticker discarded since lastTick
+// because the previous read (or the scheduler) overran the sample period.
+func (fs *forceSensor) countDroppedTicks(lastTick, tick time.Time, period time.Duration) {
+	if lastTick.IsZero() {
+		return
+	}
+	missed := int64((tick.Sub(lastTick)+period/2)/period) - 1
+	if missed > 0 {
+		fs.droppedTicks.Add(missed)
+	}
+}
+
+// setFault records or clears the sensor fault, logging transitions once.
+func (fs *forceSensor) setFault(fault string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fault == fs.fault {
+		return
+	}
+	if fault != "" {
+		fs.logger.Errorf("force sensor fault: %s", fault)
+	} else {
+		fs.logger.Infof("force sensor fault cleared (was: %s)", fs.fault)
+	}
+	fs.fault = fault
+	fs.faulted.Store(fault != "")
+}
+
 func (fs *forceSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	command, ok := cmd["command"].(string)
 	if !ok {
@@ -305,17 +619,30 @@ func (fs *forceSensor) DoCommand(ctx context.Context, cmd map[string]interface{}
 		return fs.handleStartCapture(cmd)
 	case "end_capture":
 		return fs.handleEndCapture()
+	case "read_force":
+		return fs.handleReadForce(ctx, cmd)
+	case "set_mock_profile":
+		return fs.handleSetMockProfile(ctx, cmd)
 	default:
 		return nil, fmt.Errorf("unknown command: %s", command)
 	}
 }
 
+// handleSetMockProfile forwards to the wrapped mock cell: the use_mock_curve
+// cell, or a mock-load-cell configured as load_cell.
+func (fs *forceSensor) handleSetMockProfile(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if fs.loadCell == nil {
+		return nil, fmt.Errorf("set_mock_profile requires use_mock_curve or a mock-load-cell as load_cell")
+	}
+	return fs.loadCell.DoCommand(ctx, cmd)
+}
+
 func (fs *forceSensor) handleStartCapture(cmd map[string]interface{}) (map[string]interface{}, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	if fs.state != captureIdle {
-		return nil, fmt.Errorf("capture already in progress (state: %d)", fs.state)
+	if state := fs.captureState(); state != captureIdle {
+		return nil, fmt.Errorf("capture already in progress (state: %d)", state)
 	}
 
 	// Reset and extract trial metadata from command
@@ -330,23 +657,29 @@ func (fs *forceSensor) handleStartCapture(cmd map[string]interface{}) (map[strin
 	} else if cycleCount, ok := cmd["cycle_count"].(int); ok {
 		fs.cycleCount = cycleCount
 	}
+	fs.metadata = parseCaptureMetadata(cmd)
 
-	fs.state = captureWaiting
-	fs.samples = fs.samples[:0]
+	fs.ring.reset()
+	fs.droppedTicks.Store(0)
+	fs.setCaptureState(captureWaiting)
 
 	// Start timeout timer
 	fs.timeoutTimer = time.AfterFunc(fs.captureTimeout, func() {
 		fs.mu.Lock()
 		defer fs.mu.Unlock()
-		if fs.state != captureIdle {
+		if fs.captureState() != captureIdle {
 			fs.logger.Errorf("capture timeout: end_capture not called within %v", fs.captureTimeout)
-			fs.state = captureIdle
+			fs.setCaptureState(captureIdle)
 		}
 	})
 
-	// If using mock reader, simulate contact starting
-	if mock, ok := fs.reader.(*mockForceReader); ok {
-		mock.SetContact(true)
+	// A replay plays the next recorded profile over the capture, and the
+	// use_mock_curve cell puts the kettle down
+	if fs.replay != nil {
+		fs.replay.captureStarted(fs.cycleCount)
+	}
+	if fs.mock != nil {
+		fs.mock.captureStarted(fs.cycleCount)
 	}
 
 	fs.logger.Infof("capture started, waiting for non-zero reading (threshold: %.2f)", fs.zeroThreshold)
@@ -357,7 +690,8 @@ func (fs *forceSensor) handleEndCapture() (map[string]interface{}, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	if fs.state == captureIdle {
+	prevState := fs.state.Swap(int32(captureIdle))
+	if captureState(prevState) == captureIdle {
 		return nil, fmt.Errorf("no capture in progress")
 	}
 
@@ -367,44 +701,130 @@ func (fs *forceSensor) handleEndCapture() (map[string]interface{}, error) {
 		fs.timeoutTimer = nil
 	}
 
-	// If using mock reader, simulate contact ending
-	if mock, ok := fs.reader.(*mockForceReader); ok {
-		mock.SetContact(false)
+	if fs.replay != nil {
+		fs.replay.captureEnded()
+	}
+	if fs.mock != nil {
+		fs.mock.captureEnded()
 	}
 
-	sampleCount := len(fs.samples)
+	samples, cellSamples := fs.ring.snapshot()
+	sampleCount := len(samples)
 	var maxForce float64
 	if sampleCount > 0 {
-		maxForce = fs.samples[0]
-		for _, v := range fs.samples[1:] {
+		maxForce = samples[0]
+		for _, v := range samples[1:] {
 			if v > maxForce {
 				maxForce = v
 			}
 		}
 	}
+	droppedTicks := fs.droppedTicks.Load()
 
-	prevState := fs.state
-	fs.state = captureIdle
+	// Impulse: force integrated over the capture (force units x seconds)
+	var impulse float64
+	for _, v := range samples {
+		impulse += v
+	}
+	impulse /= float64(fs.sampleRateHz)
 
 	// Clear trial metadata so should_sync becomes false
 	trialID := fs.trialID
 	cycleCount := fs.cycleCount
+	metadata := fs.metadata
 	fs.trialID = ""
 	fs.cycleCount = 0
+	fs.metadata = nil
 
 	stateStr := "waiting"
-	if prevState == captureActive {
+	if captureState(prevState) == captureActive {
 		stateStr = "capturing"
 	}
 
 	fs.logger.Infof("capture ended (was %s): %d samples, max force: %.2f", stateStr, sampleCount, maxForce)
-	return map[string]interface{}{
-		"status":       "completed",
-		"sample_count": sampleCount,
-		"max_force":    maxForce,
-		"trial_id":     trialID,
-		"cycle_count":  cycleCount,
-	}, nil
+	samplesInterface := make([]interface{}, len(samples))
+	for i, v := range samples {
+		samplesInterface[i] = v
+	}
+	result := map[string]interface{}{
+		"status":        "completed",
+		"samples":       samplesInterface,
+		"sample_count":  sampleCount,
+		"max_force":     maxForce,
+		"impulse":       impulse,
+		"trial_id":      trialID,
+		"cycle_count":   cycleCount,
+		"dropped_ticks": droppedTicks,
+	}
+	metadata.addTo(result)
+	impact := classifyImpact(fs.impact, samples, captureState(prevState) == captureActive)
+	if droppedTicks > 0 {
+		fs.logger.Warnf("dropped %d of the force sensor's sample ticks: reads could not keep up with %d Hz", droppedTicks, fs.sampleRateHz)
+	}
+	if fs.fault != "" {
+		result["fault"] = fs.fault
+	}
+
+	if len(fs.cells) > 0 {
+		cellMax := make(map[string]interface{}, len(fs.cells))
+		for i, cell := range fs.cells {
+			var m float64
+			for _, forces := range cellSamples {
+				if forces[i] > m {
+					m = forces[i]
+				}
+			}
+			cellMax[cell.Name] = m
+		}
+		result["cell_max_force"] = cellMax
+
+		landing := fs.landingResult(cellSamples)
+		for k, v := range landing {
+			result[k] = v
+		}
+		if offCenter, _ := landing["off_center"].(bool); offCenter {
+			fs.logger.Warnf("off-center landing: center of pressure %.2f from expected center (tolerance %.2f)",
+				landing["landing_offset"], fs.offCenterTolerance)
+			impact.flags = append(impact.flags, flagOffCenter)
+		}
+	}
+
+	for k, v := range impact.toMap() {
+		result[k] = v
+	}
+	if len(impact.flags) > 0 {
+		fs.logger.Warnf("put-down flagged %v (peaks: %d, max force: %.2f)", impact.flags, impact.peakCount, maxForce)
+	}
+
+	return result, nil
+}
+
+// handleReadForce averages "samples" (default 1) direct reads of the load
+// cell, for checks made while no capture is running.
+func (fs *forceSensor) handleReadForce(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if fs.captureState() != captureIdle {
+		return nil, fmt.Errorf("read_force not allowed while a capture is in progress")
+	}
+	samples := 1
+	if n, ok := toFloat64(cmd["samples"]); ok {
+		samples = int(n)
+	}
+	if samples < 1 {
+		return nil, fmt.Errorf("samples must be at least 1")
+	}
+
+	timeout := max(fs.readTimeout, minReadTimeout)
+	var sum float64
+	for i := 0; i < samples; i++ {
+		readCtx, cancel := context.WithTimeout(ctx, timeout)
+		force, _, err := fs.readSample(readCtx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("reading force: %w", err)
+		}
+		sum += force
+	}
+	return map[string]interface{}{"force": sum / float64(samples), "samples": samples}, nil
 }
 
 func (fs *forceSensor) Close(context.Context) error {
@@ -413,5 +833,10 @@ func (fs *forceSensor) Close(context.Context) error {
 		fs.timeoutTimer.Stop()
 	}
 	fs.mu.Unlock()
+
+	if fs.cancelFunc != nil {
+		fs.cancelFunc()
+		fs.wg.Wait()
+	}
 	return nil
 }
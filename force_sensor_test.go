@@ -33,10 +33,7 @@ func TestForceSensorConfig(t *testing.T) {
 	})
 
 	t.Run("use_mock_curve is optional flag", func(t *testing.T) {
-		cfg := &ForceSensorConfig{
-			LoadCell:     "my-adc-sensor",
-			UseMockCurve: true,
-		}
+		cfg := &ForceSensorConfig{LoadCell: "my-adc-sensor", UseMockCurve: true}
 		deps, _, err := cfg.Validate("test")
 		if err != nil {
 			t.Fatalf("Validate failed: %v", err)
@@ -45,21 +42,42 @@ func TestForceSensorConfig(t *testing.T) {
 		if len(deps) != 1 {
 			t.Errorf("expected 1 dependency, got %d", len(deps))
 		}
+
+		cfg = &ForceSensorConfig{UseMockCurve: true}
+		deps, _, err = cfg.Validate("test")
+		if err != nil {
+			t.Fatalf("Validate without load_cell failed: %v", err)
+		}
+		if len(deps) != 0 {
+			t.Errorf("expected no dependencies, got %v", deps)
+		}
 	})
+
 }
 
-// newTestForceSensor creates a force sensor with mock reader for testing
+// newMockCellReader reads a mock-load-cell with the kettle already down
+// through the same sensorForceReader path as hardware.
+func newMockCellReader(t *testing.T) forceReader {
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{}, resource.Dependencies{})
+	lc.setContact(true)
+	paths, err := parseForcePaths("", nil, "")
+	if err != nil {
+		t.Fatalf("parseForcePaths failed: %v", err)
+	}
+	return newSensorForceReader(lc, paths, "")
+}
+
+// newTestForceSensor creates a force sensor reading a mock load cell for testing
 func newTestForceSensor(t *testing.T) *forceSensor {
 	return &forceSensor{
 		name:           resource.NewName(resource.APINamespaceRDK.WithComponentType("sensor"), "test"),
 		logger:         logging.NewTestLogger(t),
-		reader:         newMockForceReader(),
+		reader:         newMockCellReader(t),
 		sampleRateHz:   100,
 		bufferSize:     100,
 		zeroThreshold:  5.0,
 		captureTimeout: 10 * time.Second,
-		samples:        make([]float64, 0, 100),
-		state:          captureIdle,
+		ring:           newSampleRing(100, 0),
 	}
 }
 
@@ -74,7 +92,8 @@ func TestForceSensor_StateMachine(t *testing.T) {
 
 	t.Run("start_capture transitions to waiting", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		result, err := fs.handleStartCapture(map[string]interface{}{})
 		if err != nil {
@@ -94,7 +113,8 @@ func TestForceSensor_StateMachine(t *testing.T) {
 
 	t.Run("first reading above threshold transitions to active", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{})
 
@@ -111,7 +131,8 @@ func TestForceSensor_StateMachine(t *testing.T) {
 
 	t.Run("end_capture transitions back to idle", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{})
 		time.Sleep(50 * time.Millisecond)
@@ -132,7 +153,8 @@ func TestForceSensor_StateMachine(t *testing.T) {
 
 	t.Run("double start_capture errors", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		_, err := fs.handleStartCapture(map[string]interface{}{})
 		if err != nil {
@@ -169,11 +191,14 @@ func TestForceSensor_ShouldSync(t *testing.T) {
 
 	t.Run("true during capture with trial metadata", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{
 			"trial_id":    "trial-123",
 			"cycle_count": 5,
+			"specimen_id": "SN-1042",
+			"operator":    "sam",
 		})
 
 		readings, _ := fs.Readings(context.Background(), nil)
@@ -186,13 +211,20 @@ func TestForceSensor_ShouldSync(t *testing.T) {
 		if readings["cycle_count"] != 5 {
 			t.Errorf("expected cycle_count=5, got %v", readings["cycle_count"])
 		}
+		if readings["specimen_id"] != "SN-1042" || readings["operator"] != "sam" {
+			t.Errorf("expected specimen and operator in readings, got %v %v", readings["specimen_id"], readings["operator"])
+		}
 
-		fs.handleEndCapture()
+		result, _ := fs.handleEndCapture()
+		if result["specimen_id"] != "SN-1042" {
+			t.Errorf("expected specimen in the capture result, got %v", result["specimen_id"])
+		}
 	})
 
 	t.Run("false after end_capture", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{"trial_id": "trial-123"})
 		fs.handleEndCapture()
@@ -201,8 +233,8 @@ func TestForceSensor_ShouldSync(t *testing.T) {
 		if readings["should_sync"] != false {
 			t.Errorf("expected should_sync=false after end_capture, got %v", readings["should_sync"])
 		}
-		if readings["trial_id"] != "" {
-			t.Errorf("expected empty trial_id after end_capture, got %v", readings["trial_id"])
+		if readings["trial_id"] != "" || readings["specimen_id"] != "" {
+			t.Errorf("expected empty trial_id and specimen_id after end_capture, got %v %v", readings["trial_id"], readings["specimen_id"])
 		}
 	})
 }
@@ -213,16 +245,16 @@ func TestForceSensor_Buffer(t *testing.T) {
 		fs := &forceSensor{
 			name:           resource.NewName(resource.APINamespaceRDK.WithComponentType("sensor"), "test"),
 			logger:         logging.NewTestLogger(t),
-			reader:         newMockForceReader(),
+			reader:         newMockCellReader(t),
 			sampleRateHz:   500, // Fast sampling to fill buffer
 			bufferSize:     bufferSize,
 			zeroThreshold:  5.0,
 			captureTimeout: 10 * time.Second,
-			samples:        make([]float64, 0, bufferSize),
-			state:          captureIdle,
+			ring:           newSampleRing(bufferSize, 0),
 		}
 
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{})
 		time.Sleep(100 * time.Millisecond)
@@ -242,7 +274,9 @@ func TestForceSensor_MaxForce(t *testing.T) {
 	t.Run("correctly identifies max from samples", func(t *testing.T) {
 		fs := newTestForceSensor(t)
 		// Inject known samples directly
-		fs.samples = []float64{10.0, 50.0, 30.0, 25.0}
+		for _, f := range []float64{10.0, 50.0, 30.0, 25.0} {
+			fs.ring.push(f, nil)
+		}
 
 		readings, _ := fs.Readings(context.Background(), nil)
 		maxForce, ok := readings["max_force"].(float64)
@@ -258,7 +292,8 @@ func TestForceSensor_MaxForce(t *testing.T) {
 func TestForceSensor_ThreadSafety(t *testing.T) {
 	t.Run("concurrent reads during active sampling", func(t *testing.T) {
 		fs := newTestForceSensor(t)
-		go fs.samplingLoop()
+		fs.startSampling()
+		defer fs.Close(context.Background())
 
 		fs.handleStartCapture(map[string]interface{}{})
 
@@ -281,3 +316,102 @@ func TestForceSensor_ThreadSafety(t *testing.T) {
 		fs.handleEndCapture()
 	})
 }
+
+// blockingForceReader counts reads and blocks while block is set, ignoring ctx
+type blockingForceReader struct {
+	mu          sync.Mutex
+	reads       int
+	block       chan struct{}
+	hadDeadline bool
+}
+
+func (r *blockingForceReader) ReadForce(ctx context.Context) (float64, error) {
+	r.mu.Lock()
+	r.reads++
+	_, r.hadDeadline = ctx.Deadline()
+	block := r.block
+	r.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+	return 100, nil
+}
+
+func (r *blockingForceReader) readCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads
+}
+
+func TestForceSensor_Lifecycle(t *testing.T) {
+	t.Run("Close stops the sampling loop", func(t *testing.T) {
+		reader := &blockingForceReader{}
+		fs := newTestForceSensor(t)
+		fs.reader = reader
+		fs.startSampling()
+
+		fs.handleStartCapture(map[string]interface{}{})
+		time.Sleep(50 * time.Millisecond)
+		if reader.readCount() == 0 {
+			t.Fatal("expected reads while capturing")
+		}
+		if !reader.hadDeadline {
+			t.Error("expected per-read context with a deadline")
+		}
+
+		if err := fs.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		after := reader.readCount()
+		time.Sleep(50 * time.Millisecond)
+		if reader.readCount() != after {
+			t.Error("sampling continued after Close")
+		}
+	})
+
+	t.Run("read timeout derives from sample period", func(t *testing.T) {
+		fs := newTestForceSensor(t)
+		fs.sampleRateHz = 50
+		fs.startSampling()
+		defer fs.Close(context.Background())
+		if fs.readTimeout != 100*time.Millisecond {
+			t.Errorf("expected 5 sample periods (100ms), got %v", fs.readTimeout)
+		}
+	})
+
+	t.Run("stuck read is a fault, not a blocked loop", func(t *testing.T) {
+		reader := &blockingForceReader{block: make(chan struct{})}
+		fs := newTestForceSensor(t)
+		fs.reader = reader
+		fs.readTimeout = 20 * time.Millisecond
+		fs.startSampling()
+
+		fs.handleStartCapture(map[string]interface{}{})
+		time.Sleep(100 * time.Millisecond)
+
+		readings, _ := fs.Readings(context.Background(), nil)
+		if readings["fault"] == "" {
+			t.Error("expected a fault while the load cell read is stuck")
+		}
+		if reader.readCount() != 1 {
+			t.Errorf("expected no new reads while one is stuck, got %d", reader.readCount())
+		}
+		result, _ := fs.handleEndCapture()
+		if result["fault"] == nil {
+			t.Error("expected fault in end_capture result")
+		}
+
+		// Close must not wait on the stuck hardware read
+		closed := make(chan struct{})
+		go func() {
+			fs.Close(context.Background())
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("Close blocked on a stuck read")
+		}
+		close(reader.block)
+	})
+}
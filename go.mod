@@ -2,7 +2,10 @@ module kettlecycletest
 
 go 1.25.1
 
-require go.viam.com/rdk v0.109.0
+require (
+	github.com/golang/geo v0.0.0-20230421003525-6adc56603217
+	go.viam.com/rdk v0.109.0
+)
 
 require (
 	cloud.google.com/go v0.115.1 // indirect
@@ -78,7 +81,6 @@ require (
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/golang-jwt/jwt/v4 v4.5.2 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	github.com/golang/geo v0.0.0-20230421003525-6adc56603217 // indirect
 	github.com/golang/groupcache v0.0.0-20241129210726-2c02b8208cf8 // indirect
 	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
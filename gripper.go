@@ -0,0 +1,77 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+)
+
+// faultGripFailed is the fault reason when the gripper does not hold the
+// kettle's handle before lift.
+const faultGripFailed = "grip_failed"
+
+// Image tags recording the grip state, for correlating grip failures with
+// vision.
+const (
+	tagGripHeld   = "grip:held"
+	tagGripFailed = "grip:failed"
+)
+
+// gripKettle closes the gripper on the handle and checks it is holding
+// something. The holding state, not Grab's return value, decides: a gripper
+// can close fully on nothing. The state is recorded in the result and grip
+// failures are counted on the trial.
+func (s *kettleCycleTestController) gripKettle(ctx context.Context, result map[string]interface{}) (bool, error) {
+	grabbed, err := s.gripper.Grab(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("gripping kettle: %w", err)
+	}
+	status, err := s.gripper.IsHoldingSomething(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("checking grip: %w", err)
+	}
+	held := status.IsHoldingSomething
+	result["grip_held"] = held
+	if held {
+		return true, nil
+	}
+
+	s.logger.Warnf("gripper is not holding the handle (grab reported %v)", grabbed)
+	s.mu.Lock()
+	if trial := s.cycleTrialLocked(); trial != nil {
+		trial.gripFailures++
+	}
+	s.mu.Unlock()
+	return false, nil
+}
+
+// releaseKettle opens the gripper, logging rather than failing the cycle
+// when it cannot.
+func (s *kettleCycleTestController) releaseKettle(ctx context.Context) {
+	if err := s.gripper.Open(ctx, nil); err != nil {
+		s.logger.Warnf("failed to release kettle: %v", err)
+	}
+}
+
+func (s *kettleCycleTestController) handleGrip(ctx context.Context) (map[string]interface{}, error) {
+	if s.gripper == nil {
+		return nil, fmt.Errorf("grip requires a configured gripper")
+	}
+	if _, err := s.gripper.Grab(ctx, nil); err != nil {
+		return nil, fmt.Errorf("gripping kettle: %w", err)
+	}
+	status, err := s.gripper.IsHoldingSomething(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("checking grip: %w", err)
+	}
+	return map[string]interface{}{"held": status.IsHoldingSomething}, nil
+}
+
+func (s *kettleCycleTestController) handleRelease(ctx context.Context) (map[string]interface{}, error) {
+	if s.gripper == nil {
+		return nil, fmt.Errorf("release requires a configured gripper")
+	}
+	if err := s.gripper.Open(ctx, nil); err != nil {
+		return nil, fmt.Errorf("releasing kettle: %w", err)
+	}
+	return map[string]interface{}{"status": "released"}, nil
+}
@@ -0,0 +1,112 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newTestGripper returns a gripper that reports holding as configured and
+// counts opens.
+func newTestGripper(holding bool, opens *int) *inject.Gripper {
+	g := inject.NewGripper("gripper")
+	g.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		return holding, nil
+	}
+	g.IsHoldingSomethingFunc = func(ctx context.Context, extra map[string]interface{}) (gripper.HoldingStatus, error) {
+		return gripper.HoldingStatus{IsHoldingSomething: holding}, nil
+	}
+	g.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		*opens++
+		return nil
+	}
+	return g
+}
+
+func TestController_Gripper(t *testing.T) {
+	t.Run("config adds gripper dependency", func(t *testing.T) {
+		cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", Gripper: "g"}
+		deps, _, err := cfg.Validate("test")
+		if err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+		if deps[len(deps)-1] != "g" {
+			t.Errorf("expected gripper in deps, got %v", deps)
+		}
+	})
+
+	t.Run("handle not held faults before lift", func(t *testing.T) {
+		kctrl := newTestController(t)
+		opens := 0
+		kctrl.gripper = newTestGripper(false, &opens)
+		lifted := false
+		pourPrep := inject.NewSwitch("pour-prep")
+		pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			lifted = true
+			return nil
+		}
+		kctrl.pourPrep = pourPrep
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "faulted" || result["fault"] != faultGripFailed || result["grip_held"] != false {
+			t.Errorf("expected grip_failed fault, got %v", result)
+		}
+		if lifted {
+			t.Error("expected the arm not to lift without a grip")
+		}
+		if opens != 1 {
+			t.Errorf("expected the gripper to be released, got %d opens", opens)
+		}
+		if state := kctrl.GetState(); state["grip_failures"] != 1 {
+			t.Errorf("expected one grip failure in status, got %v", state["grip_failures"])
+		}
+
+		history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+		record := history["cycles"].([]interface{})[0].(map[string]interface{})
+		if record["grip_held"] != false || record["fault"] != faultGripFailed {
+			t.Errorf("expected grip failure in history, got %v", record)
+		}
+	})
+
+	t.Run("held handle completes and releases", func(t *testing.T) {
+		kctrl := newTestController(t)
+		opens := 0
+		kctrl.gripper = newTestGripper(true, &opens)
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "completed" || result["grip_held"] != true {
+			t.Errorf("expected completed cycle with grip, got %v", result)
+		}
+		if opens != 1 {
+			t.Errorf("expected release after put-down, got %d opens", opens)
+		}
+	})
+
+	t.Run("grip and release commands", func(t *testing.T) {
+		kctrl := newTestController(t)
+		if _, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "grip"}); err == nil {
+			t.Error("expected grip to fail without a gripper")
+		}
+
+		opens := 0
+		kctrl.gripper = newTestGripper(true, &opens)
+		result, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "grip"})
+		if err != nil || result["held"] != true {
+			t.Errorf("expected held grip, got %v, %v", result, err)
+		}
+		if _, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "release"}); err != nil || opens != 1 {
+			t.Errorf("expected release to open the gripper, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package kettlecycletest
+
+import (
+	"time"
+)
+
+const defaultHistorySize = 100
+
+// cycleRecord summarizes one executed cycle for the history command.
+type cycleRecord struct {
+	trialID    string
+	cycleCount int
+	startedAt  time.Time
+	duration   time.Duration
+	status     string
+	manual     bool // an operator-requested cycle outside any trial's count
+	fault      string
+	errMsg     string // why an errored cycle stopped
+	phase      string // phase an errored or panicked cycle stopped in
+	warnings   []string
+
+	// Force capture summary, set when the force sensor returned a result
+	hasForce     bool
+	maxForce     float64
+	impulse      float64
+	impactFlags  []string
+	driftScore   *float64
+	driftOutside *float64 // fraction of the profile outside the drift envelope
+
+	spcViolations []string
+
+	restingWeight *float64 // pre-lift weight, when checked
+	liftResidual  *float64 // force left at pour-prep, when checked
+	gripHeld      *bool    // gripper holding state before lift, when a gripper is configured
+
+	poseDeviations map[string]interface{} // per saved position, when expected poses are configured
+	armTelemetry   map[string]interface{} // joint travel and motion of the cycle, when arm telemetry is configured
+	phaseTimings   map[string]interface{} // ms per phase that ran
+	annotations    []annotation           // operator notes on this cycle
+	metadata       map[string]interface{} // the trial's start metadata
+}
+
+func (r cycleRecord) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"trial_id":       r.trialID,
+		"cycle_count":    r.cycleCount,
+		"started_at":     r.startedAt.Format(time.RFC3339Nano),
+		"duration_ms":    r.duration.Milliseconds(),
+		"status":         r.status,
+		"manual":         r.manual,
+		"fault":          r.fault,
+		"warnings":       toInterfaceList(r.warnings),
+		"spc_violations": toInterfaceList(r.spcViolations),
+	}
+	if r.errMsg != "" {
+		m["error"] = r.errMsg
+	}
+	if r.phase != "" {
+		m["phase"] = r.phase
+	}
+	if r.hasForce {
+		m["max_force"] = r.maxForce
+		m["impulse"] = r.impulse
+		m["impact_flags"] = toInterfaceList(r.impactFlags)
+	}
+	if r.driftScore != nil {
+		m["drift_score"] = *r.driftScore
+	}
+	if r.driftOutside != nil {
+		m["drift_outside_fraction"] = *r.driftOutside
+	}
+	if r.restingWeight != nil {
+		m["resting_weight"] = *r.restingWeight
+	}
+	if r.liftResidual != nil {
+		m["lift_residual"] = *r.liftResidual
+	}
+	if r.gripHeld != nil {
+		m["grip_held"] = *r.gripHeld
+	}
+	if r.poseDeviations != nil {
+		m["pose_deviations"] = r.poseDeviations
+	}
+	if r.armTelemetry != nil {
+		m["arm_telemetry"] = r.armTelemetry
+	}
+	if r.phaseTimings != nil {
+		m["phase_timings_ms"] = r.phaseTimings
+	}
+	if len(r.annotations) > 0 {
+		m["annotations"] = annotationList(r.annotations)
+	}
+	if r.metadata != nil {
+		m["metadata"] = r.metadata
+	}
+	return m
+}
+
+// newCycleRecord builds the history entry from a cycle's result.
+func newCycleRecord(trialID string, cycleCount int, startedAt time.Time, duration time.Duration, result map[string]interface{}) cycleRecord {
+	r := cycleRecord{
+		trialID:       trialID,
+		cycleCount:    cycleCount,
+		startedAt:     startedAt,
+		duration:      duration,
+		warnings:      stringList(result["warnings"]),
+		spcViolations: stringList(result["spc_violations"]),
+	}
+	r.status, _ = result["status"].(string)
+	r.manual, _ = result["manual"].(bool)
+	r.fault, _ = result["fault"].(string)
+	r.errMsg, _ = result["error"].(string)
+	r.phase, _ = result["phase"].(string)
+	if score, ok := result["drift_score"].(float64); ok {
+		r.driftScore = &score
+	}
+	if outside, ok := result["drift_outside_fraction"].(float64); ok {
+		r.driftOutside = &outside
+	}
+	if weight, ok := result["resting_weight"].(float64); ok {
+		r.restingWeight = &weight
+	}
+	if residual, ok := result["lift_residual"].(float64); ok {
+		r.liftResidual = &residual
+	}
+	if held, ok := result["grip_held"].(bool); ok {
+		r.gripHeld = &held
+	}
+	r.poseDeviations, _ = result["pose_deviations"].(map[string]interface{})
+	r.armTelemetry, _ = result["arm_telemetry"].(map[string]interface{})
+	r.phaseTimings, _ = result["phase_timings_ms"].(map[string]interface{})
+	if capture, ok := result["force_capture"].(map[string]interface{}); ok {
+		r.hasForce = true
+		r.maxForce, _ = toFloat64(capture["max_force"])
+		r.impulse, _ = toFloat64(capture["impulse"])
+		r.impactFlags = stringList(capture["impact_flags"])
+	}
+	return r
+}
+
+// cycleHistory keeps the most recent cycle records, oldest first.
+type cycleHistory struct {
+	size    int
+	records []cycleRecord
+}
+
+func newCycleHistory(size int) *cycleHistory {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &cycleHistory{size: size}
+}
+
+func (h *cycleHistory) add(r cycleRecord) {
+	if len(h.records) >= h.size {
+		h.records = append(h.records[:0], h.records[1:]...)
+	}
+	h.records = append(h.records, r)
+}
+
+// annotate adds a note to the record of the cycle it was made on, if that
+// cycle has already been recorded.
+func (h *cycleHistory) annotate(trialID string, a annotation) {
+	for i := len(h.records) - 1; i >= 0; i-- {
+		r := &h.records[i]
+		if r.trialID == trialID && r.cycleCount == a.cycleCount {
+			r.annotations = append(r.annotations, a)
+			return
+		}
+	}
+}
+
+// query returns up to limit of the newest records (all when limit <= 0),
+// optionally only those of one trial.
+func (h *cycleHistory) query(trialID string, limit int) []interface{} {
+	var matched []cycleRecord
+	for _, r := range h.records {
+		if trialID == "" || r.trialID == trialID {
+			matched = append(matched, r)
+		}
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	out := make([]interface{}, len(matched))
+	for i, r := range matched {
+		out[i] = r.toMap()
+	}
+	return out
+}
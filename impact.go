@@ -0,0 +1,218 @@
+package kettlecycletest
+
+import (
+	"fmt"
+	"math"
+)
+
+// Put-down impact flags reported by end_capture.
+const (
+	flagHardLanding    = "hard_landing"    // peak force above hard_landing_force
+	flagDoubleBounce   = "double_bounce"   // more than one force peak
+	flagNoContact      = "no_contact"      // capture ended before any non-zero reading
+	flagAbnormalWeight = "abnormal_weight" // steady-state force outside the expected weight band
+	flagOffCenter      = "off_center"      // center of pressure beyond off_center_tolerance (load_cells only)
+)
+
+var impactFlags = []string{flagHardLanding, flagDoubleBounce, flagNoContact, flagAbnormalWeight, flagOffCenter}
+
+// ImpactConfig sets the limits the force sensor judges each put-down against.
+// no_contact and double_bounce are always checked; the other flags are
+// enabled by their limits.
+type ImpactConfig struct {
+	HardLandingForce   float64 `json:"hard_landing_force,omitempty"`   // peak above this is a hard landing (0 disables)
+	BounceProminence   float64 `json:"bounce_prominence,omitempty"`    // dip and re-rise that counts as another peak (default: 25% of peak)
+	ExpectedWeight     float64 `json:"expected_weight,omitempty"`      // steady-state force of the resting kettle (0 disables)
+	WeightTolerance    float64 `json:"weight_tolerance,omitempty"`     // allowed deviation from expected_weight (default: 10% of it)
+	SteadyStateSamples int     `json:"steady_state_samples,omitempty"` // trailing samples averaged for steady state (default: 10)
+}
+
+func (cfg *ImpactConfig) validate() error {
+	nonNegative := map[string]float64{
+		"hard_landing_force":   cfg.HardLandingForce,
+		"bounce_prominence":    cfg.BounceProminence,
+		"expected_weight":      cfg.ExpectedWeight,
+		"weight_tolerance":     cfg.WeightTolerance,
+		"steady_state_samples": float64(cfg.SteadyStateSamples),
+	}
+	for name, v := range nonNegative {
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if cfg.WeightTolerance != 0 && cfg.ExpectedWeight == 0 {
+		return fmt.Errorf("weight_tolerance requires expected_weight")
+	}
+	return nil
+}
+
+// impactResult is the judgement of one put-down.
+type impactResult struct {
+	flags       []string
+	peakCount   int
+	steadyState float64
+	hasSteady   bool
+}
+
+// toMap returns the end_capture fields for the result.
+func (r impactResult) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"impact_flags": toInterfaceList(r.flags),
+		"peak_count":   r.peakCount,
+	}
+	if r.hasSteady {
+		m["steady_state_force"] = r.steadyState
+	}
+	return m
+}
+
+// classifyImpact judges a captured put-down. contact is false when the
+// capture never saw a non-zero reading.
+func classifyImpact(cfg ImpactConfig, samples []float64, contact bool) impactResult {
+	if !contact || len(samples) == 0 {
+		return impactResult{flags: []string{flagNoContact}}
+	}
+
+	peak := samples[0]
+	for _, v := range samples[1:] {
+		peak = math.Max(peak, v)
+	}
+
+	prominence := cfg.BounceProminence
+	if prominence <= 0 {
+		prominence = 0.25 * peak
+	}
+
+	var result impactResult
+	result.peakCount = countPeaks(samples, prominence)
+	result.steadyState = steadyStateForce(samples, cfg.SteadyStateSamples)
+	result.hasSteady = true
+
+	if cfg.HardLandingForce > 0 && peak > cfg.HardLandingForce {
+		result.flags = append(result.flags, flagHardLanding)
+	}
+	if result.peakCount > 1 {
+		result.flags = append(result.flags, flagDoubleBounce)
+	}
+	if cfg.ExpectedWeight > 0 {
+		tolerance := cfg.WeightTolerance
+		if tolerance <= 0 {
+			tolerance = 0.1 * cfg.ExpectedWeight
+		}
+		if math.Abs(result.steadyState-cfg.ExpectedWeight) > tolerance {
+			result.flags = append(result.flags, flagAbnormalWeight)
+		}
+	}
+	return result
+}
+
+// countPeaks counts rises of at least prominence above the preceding valley.
+// Capture starts at the first non-zero reading, so the first valley is zero.
+func countPeaks(samples []float64, prominence float64) int {
+	peaks := 0
+	rising := false
+	valley, top := 0.0, 0.0
+	for _, f := range samples {
+		if rising {
+			top = math.Max(top, f)
+			if f <= top-prominence {
+				rising = false
+				valley = f
+			}
+			continue
+		}
+		valley = math.Min(valley, f)
+		if f >= valley+prominence {
+			rising = true
+			top = f
+			peaks++
+		}
+	}
+	return peaks
+}
+
+// steadyStateForce averages the trailing samples, where the kettle rests.
+func steadyStateForce(samples []float64, window int) float64 {
+	if window <= 0 {
+		window = 10
+	}
+	tail := samples[max(0, len(samples)-window):]
+	var sum float64
+	for _, v := range tail {
+		sum += v
+	}
+	return sum / float64(len(tail))
+}
+
+// Controller actions for impact flags.
+const (
+	impactActionFault  = "fault"
+	impactActionWarn   = "warn"
+	impactActionIgnore = "ignore"
+)
+
+// validateImpactRules checks the controller's flag-to-action map.
+func validateImpactRules(rules map[string]string) error {
+	for flag, action := range rules {
+		known := false
+		for _, f := range impactFlags {
+			known = known || f == flag
+		}
+		if !known {
+			return fmt.Errorf("unknown impact flag %q (must be one of %v)", flag, impactFlags)
+		}
+		switch action {
+		case impactActionFault, impactActionWarn, impactActionIgnore:
+		default:
+			return fmt.Errorf("impact flag %q: action %q is invalid (must be %q, %q or %q)",
+				flag, action, impactActionFault, impactActionWarn, impactActionIgnore)
+		}
+	}
+	return nil
+}
+
+// evaluateImpactRules splits the flags from an end_capture result into those
+// that fault the trial and those that only warn. Unlisted flags warn.
+func evaluateImpactRules(rules map[string]string, captureResult map[string]interface{}) (faults, warnings []string) {
+	for _, flag := range stringList(captureResult["impact_flags"]) {
+		action, ok := rules[flag]
+		if !ok {
+			action = impactActionWarn
+		}
+		switch action {
+		case impactActionFault:
+			faults = append(faults, flag)
+		case impactActionWarn:
+			warnings = append(warnings, flag)
+		}
+	}
+	return faults, warnings
+}
+
+// stringList reads a list of strings from a DoCommand result, which holds
+// []interface{} after crossing gRPC and may hold []string in-process.
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// toInterfaceList converts strings to the list type DoCommand results carry.
+func toInterfaceList(list []string) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, s := range list {
+		out[i] = s
+	}
+	return out
+}
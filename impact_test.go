@@ -0,0 +1,181 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClassifyImpact(t *testing.T) {
+	landing := []float64{20, 90, 160, 210, 190, 180, 180, 180}
+
+	t.Run("clean landing has no flags", func(t *testing.T) {
+		result := classifyImpact(ImpactConfig{HardLandingForce: 250, ExpectedWeight: 180, SteadyStateSamples: 3}, landing, true)
+		if len(result.flags) != 0 || result.peakCount != 1 {
+			t.Errorf("expected one peak and no flags, got %+v", result)
+		}
+	})
+
+	t.Run("hard landing", func(t *testing.T) {
+		result := classifyImpact(ImpactConfig{HardLandingForce: 200}, landing, true)
+		if !hasFlag(result.flags, flagHardLanding) {
+			t.Errorf("expected hard_landing, got %v", result.flags)
+		}
+	})
+
+	t.Run("double bounce", func(t *testing.T) {
+		bounce := []float64{50, 200, 40, 10, 150, 180, 180}
+		result := classifyImpact(ImpactConfig{}, bounce, true)
+		if result.peakCount != 2 || !hasFlag(result.flags, flagDoubleBounce) {
+			t.Errorf("expected two peaks and double_bounce, got %+v", result)
+		}
+	})
+
+	t.Run("no contact", func(t *testing.T) {
+		result := classifyImpact(ImpactConfig{}, nil, false)
+		if len(result.flags) != 1 || result.flags[0] != flagNoContact {
+			t.Errorf("expected only no_contact, got %v", result.flags)
+		}
+	})
+
+	t.Run("abnormal steady-state weight", func(t *testing.T) {
+		result := classifyImpact(ImpactConfig{ExpectedWeight: 150, SteadyStateSamples: 3}, landing, true)
+		if result.steadyState != 180 || !hasFlag(result.flags, flagAbnormalWeight) {
+			t.Errorf("expected abnormal_weight at 180, got %+v", result)
+		}
+		result = classifyImpact(ImpactConfig{ExpectedWeight: 150, WeightTolerance: 40}, landing, true)
+		if hasFlag(result.flags, flagAbnormalWeight) {
+			t.Error("expected weight within explicit tolerance")
+		}
+	})
+}
+
+func TestImpactRules(t *testing.T) {
+	if err := validateImpactRules(map[string]string{"hard_landing": "fault", "no_contact": "ignore"}); err != nil {
+		t.Errorf("expected valid rules, got %v", err)
+	}
+	if err := validateImpactRules(map[string]string{"wobble": "fault"}); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+	if err := validateImpactRules(map[string]string{"hard_landing": "panic"}); err == nil {
+		t.Error("expected error for unknown action")
+	}
+
+	rules := map[string]string{"hard_landing": "fault", "no_contact": "ignore"}
+	capture := map[string]interface{}{
+		"impact_flags": []interface{}{"hard_landing", "double_bounce", "no_contact"},
+	}
+	faults, warnings := evaluateImpactRules(rules, capture)
+	if len(faults) != 1 || faults[0] != flagHardLanding {
+		t.Errorf("faults = %v, want [hard_landing]", faults)
+	}
+	if len(warnings) != 1 || warnings[0] != flagDoubleBounce {
+		t.Errorf("warnings = %v, want [double_bounce] (unlisted flags warn)", warnings)
+	}
+}
+
+func TestForceSensor_EndCaptureNoContact(t *testing.T) {
+	fs := newTestForceSensor(t)
+	fs.handleStartCapture(map[string]interface{}{})
+
+	result, err := fs.handleEndCapture()
+	if err != nil {
+		t.Fatalf("handleEndCapture failed: %v", err)
+	}
+	flags := stringList(result["impact_flags"])
+	if len(flags) != 1 || flags[0] != flagNoContact {
+		t.Errorf("expected impact_flags=[no_contact] while still waiting, got %v", result["impact_flags"])
+	}
+}
+
+// newFlaggingForceSensor returns a force sensor whose end_capture reports flags.
+func newFlaggingForceSensor(flags ...interface{}) *inject.Sensor {
+	fs := inject.NewSensor("force")
+	fs.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "end_capture" {
+			return map[string]interface{}{"status": "completed", "impact_flags": flags}, nil
+		}
+		return map[string]interface{}{"status": "waiting"}, nil
+	}
+	fs.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"capture_state": "idle"}, nil
+	}
+	return fs
+}
+
+func TestController_ImpactRules(t *testing.T) {
+	t.Run("config requires force_sensor and valid rules", func(t *testing.T) {
+		cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p",
+			ImpactRules: map[string]string{"hard_landing": "fault"}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for impact_rules without force_sensor")
+		}
+		cfg.ForceSensor = "force"
+		if _, _, err := cfg.Validate("test"); err != nil {
+			t.Errorf("expected valid config, got %v", err)
+		}
+		cfg.ImpactRules["hard_landing"] = "explode"
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for invalid action")
+		}
+	})
+
+	t.Run("fault rule faults the trial", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.forceSensor = newFlaggingForceSensor("hard_landing", "double_bounce")
+		kctrl.cfg.ImpactRules = map[string]string{"hard_landing": "fault"}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "faulted" || result["fault"] != flagHardLanding {
+			t.Errorf("expected faulted cycle, got %v", result)
+		}
+
+		state := kctrl.GetState()
+		if state["state"] != "faulted" || state["fault"] != flagHardLanding {
+			t.Errorf("expected faulted trial, got %v", state)
+		}
+		if state["warning_count"] != 1 || state["last_warning"] != flagDoubleBounce {
+			t.Errorf("expected double_bounce warning, got %v", state)
+		}
+		if _, err := kctrl.handleStart(context.Background(), nil); err == nil {
+			t.Error("expected start to fail while a faulted trial is active")
+		}
+
+		stopped, err := kctrl.handleStop(context.Background())
+		if err != nil || stopped["fault"] != flagHardLanding {
+			t.Errorf("expected stop to report the fault, got %v, %v", stopped, err)
+		}
+	})
+
+	t.Run("flags only warn by default", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.forceSensor = newFlaggingForceSensor("abnormal_weight")
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "completed" {
+			t.Errorf("expected completed cycle, got %v", result["status"])
+		}
+		if w := stringList(result["warnings"]); len(w) != 1 || w[0] != flagAbnormalWeight {
+			t.Errorf("expected abnormal_weight warning, got %v", result["warnings"])
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	defaultInterlockPollMs = 50
+	// interlockReadTimeout bounds one input read; a read that fails or
+	// times out counts as tripped
+	interlockReadTimeout = 500 * time.Millisecond
+	// interlockWaitInterval is how often a latched trial checks for reset
+	interlockWaitInterval = 100 * time.Millisecond
+)
+
+// Interlock event kinds recorded in the trial.
+const (
+	interlockTripped = "tripped"
+	interlockCleared = "cleared"
+	interlockReset   = "reset"
+)
+
+// InterlockConfig names one safety input, either a board GPIO pin or a
+// sensor with a boolean reading.
+type InterlockConfig struct {
+	Name      string `json:"name"`                 // label used in status and events
+	Board     string `json:"board,omitempty"`      // board whose GPIO pin is read
+	Pin       string `json:"pin,omitempty"`        // GPIO pin name (requires board)
+	Sensor    string `json:"sensor,omitempty"`     // sensor with a boolean reading
+	Key       string `json:"key,omitempty"`        // reading path of the sensor's value (default: "value")
+	ActiveLow bool   `json:"active_low,omitempty"` // trips when the input reads false, e.g. normally closed e-stop loops
+}
+
+func (cfg *InterlockConfig) validate() error {
+	if cfg.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if (cfg.Board == "") == (cfg.Sensor == "") {
+		return fmt.Errorf("%s: exactly one of board or sensor is required", cfg.Name)
+	}
+	if cfg.Board != "" && cfg.Pin == "" {
+		return fmt.Errorf("%s: board requires pin", cfg.Name)
+	}
+	if cfg.Board != "" && cfg.Key != "" {
+		return fmt.Errorf("%s: key applies only to sensor inputs", cfg.Name)
+	}
+	if cfg.Key != "" {
+		if _, err := parseReadingPath(cfg.Key); err != nil {
+			return fmt.Errorf("%s: key: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateInterlocks checks each input and that names are unique.
+func validateInterlocks(interlocks []InterlockConfig) error {
+	seen := map[string]bool{}
+	for i := range interlocks {
+		if err := interlocks[i].validate(); err != nil {
+			return err
+		}
+		if seen[interlocks[i].Name] {
+			return fmt.Errorf("duplicate interlock name %q", interlocks[i].Name)
+		}
+		seen[interlocks[i].Name] = true
+	}
+	return nil
+}
+
+// interlockInput reads one configured safety input.
+type interlockInput struct {
+	cfg    InterlockConfig
+	pin    board.GPIOPin // set for board inputs
+	sensor sensor.Sensor // set for sensor inputs
+	path   readingPath
+}
+
+func newInterlockInput(deps resource.Dependencies, cfg InterlockConfig) (*interlockInput, error) {
+	in := &interlockInput{cfg: cfg}
+	if cfg.Board != "" {
+		b, err := board.FromProvider(deps, cfg.Board)
+		if err != nil {
+			return nil, fmt.Errorf("getting board: %w", err)
+		}
+		if in.pin, err = b.GPIOPinByName(cfg.Pin); err != nil {
+			return nil, fmt.Errorf("getting pin %s: %w", cfg.Pin, err)
+		}
+		return in, nil
+	}
+
+	var err error
+	if in.sensor, err = sensor.FromProvider(deps, cfg.Sensor); err != nil {
+		return nil, fmt.Errorf("getting sensor: %w", err)
+	}
+	key := cfg.Key
+	if key == "" {
+		key = "value"
+	}
+	if in.path, err = parseReadingPath(key); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// tripped reads the input and applies active_low.
+func (in *interlockInput) tripped(ctx context.Context) (bool, error) {
+	var high bool
+	if in.pin != nil {
+		v, err := in.pin.Get(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		high = v
+	} else {
+		readings, err := in.sensor.Readings(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		leaf, err := in.path.lookup(readings)
+		if err != nil {
+			return false, err
+		}
+		if high, err = toBool(leaf); err != nil {
+			return false, fmt.Errorf("path %q: %w", in.path, err)
+		}
+	}
+	return high != in.cfg.ActiveLow, nil
+}
+
+// toBool reads a boolean input value: a bool, or a number that is true when
+// non-zero.
+func toBool(v interface{}) (bool, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	if f, ok := toFloat64(v); ok {
+		return f != 0, nil
+	}
+	return false, fmt.Errorf("value is not boolean: %T (%v)", v, v)
+}
+
+// interlockEvent is one entry in the trial's interlock record.
+type interlockEvent struct {
+	at     time.Time
+	input  string
+	kind   string
+	cycle  int
+	detail string
+}
+
+func (e interlockEvent) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"at":     e.at.Format(time.RFC3339Nano),
+		"input":  e.input,
+		"event":  e.kind,
+		"cycle":  e.cycle,
+		"detail": e.detail,
+	}
+}
+
+// monitorInterlocks polls every input until ctx is cancelled.
+func (s *kettleCycleTestController) monitorInterlocks(ctx context.Context) {
+	pollMs := s.cfg.InterlockPollMs
+	if pollMs <= 0 {
+		pollMs = defaultInterlockPollMs
+	}
+	ticker := time.NewTicker(time.Duration(pollMs) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollInterlocks(ctx)
+		}
+	}
+}
+
+func (s *kettleCycleTestController) pollInterlocks(ctx context.Context) {
+	for _, in := range s.interlocks {
+		readCtx, cancel := context.WithTimeout(ctx, interlockReadTimeout)
+		tripped, err := in.tripped(readCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		detail := ""
+		if err != nil {
+			// Fail safe: an input that cannot be read is treated as tripped
+			tripped = true
+			detail = fmt.Sprintf("read failed: %v", err)
+		}
+		s.setInterlockInput(ctx, in.cfg.Name, tripped, detail)
+	}
+}
+
+// setInterlockInput records an input's state. On a new trip it latches the
+// interlock, cancels the in-flight cycle and stops the arm.
+func (s *kettleCycleTestController) setInterlockInput(ctx context.Context, name string, tripped bool, detail string) {
+	s.mu.Lock()
+	wasTripped := s.interlockTripped[name]
+	s.interlockTripped[name] = tripped
+	if tripped == wasTripped {
+		s.mu.Unlock()
+		return
+	}
+	if !tripped {
+		s.recordInterlockEventLocked(name, interlockCleared, "")
+		s.mu.Unlock()
+		s.logger.Infof("interlock %s cleared", name)
+		return
+	}
+
+	s.recordInterlockEventLocked(name, interlockTripped, detail)
+	if s.interlock == "" {
+		s.interlock = name
+	}
+	message := fmt.Sprintf("interlock %s tripped", name)
+	if detail != "" {
+		message += " (" + detail + ")"
+	}
+	s.notifyLocked("interlock_tripped", message)
+	cancelCycle := s.cycleCancel
+	s.mu.Unlock()
+
+	if cancelCycle != nil {
+		cancelCycle()
+	}
+	if err := s.arm.Stop(ctx, nil); err != nil {
+		s.logger.Errorf("failed to stop arm on interlock %s: %v", name, err)
+	}
+}
+
+// recordInterlockEventLocked adds an event to the active trial's record.
+// Callers hold s.mu.
+func (s *kettleCycleTestController) recordInterlockEventLocked(input, kind, detail string) {
+	if s.activeTrial == nil {
+		return
+	}
+	s.activeTrial.interlockEvents = append(s.activeTrial.interlockEvents, interlockEvent{
+		at:     time.Now(),
+		input:  input,
+		kind:   kind,
+		cycle:  s.activeTrial.cycleCount,
+		detail: detail,
+	})
+}
+
+// handleReset clears a latched interlock once every input has cleared.
+func (s *kettleCycleTestController) handleReset() (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.interlock == "" {
+		return nil, fmt.Errorf("not interlocked")
+	}
+	for _, in := range s.interlocks {
+		if s.interlockTripped[in.cfg.Name] {
+			return nil, fmt.Errorf("interlock %s is still tripped", in.cfg.Name)
+		}
+	}
+
+	latched := s.interlock
+	s.interlock = ""
+	s.recordInterlockEventLocked(latched, interlockReset, "")
+	s.logger.Infof("interlock %s reset", latched)
+	return map[string]interface{}{"status": "reset", "interlock": latched}, nil
+}
+
+func (s *kettleCycleTestController) interlocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interlock != ""
+}
+
+// interlockEventList returns the trial's interlock record for status.
+func (t *trialState) interlockEventList() []interface{} {
+	out := make([]interface{}, len(t.interlockEvents))
+	for i, e := range t.interlockEvents {
+		out[i] = e.toMap()
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package kettlecycletest
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestInterlockConfig(t *testing.T) {
+	bad := [][]InterlockConfig{
+		{{Board: "b", Pin: "1"}},
+		{{Name: "door"}},
+		{{Name: "door", Board: "b", Sensor: "s"}},
+		{{Name: "door", Board: "b"}},
+		{{Name: "door", Board: "b", Pin: "1", Key: "value"}},
+		{{Name: "door", Sensor: "s"}, {Name: "door", Sensor: "t"}},
+	}
+	for _, interlocks := range bad {
+		if err := validateInterlocks(interlocks); err == nil {
+			t.Errorf("expected error for %+v", interlocks)
+		}
+	}
+
+	cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", Interlocks: []InterlockConfig{
+		{Name: "estop", Board: "pi", Pin: "11", ActiveLow: true},
+		{Name: "door", Sensor: "door-switch", Key: "closed"},
+	}}
+	deps, _, err := cfg.Validate("test")
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !hasFlag(deps, "pi") || !hasFlag(deps, "door-switch") {
+		t.Errorf("expected interlock inputs in deps, got %v", deps)
+	}
+}
+
+func TestInterlockInput(t *testing.T) {
+	var high atomic.Bool
+	pin := &inject.GPIOPin{}
+	pin.GetFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		return high.Load(), nil
+	}
+	in := &interlockInput{cfg: InterlockConfig{Name: "estop", ActiveLow: true}, pin: pin}
+	if tripped, _ := in.tripped(context.Background()); !tripped {
+		t.Error("expected an active-low input reading low to be tripped")
+	}
+	high.Store(true)
+	if tripped, _ := in.tripped(context.Background()); tripped {
+		t.Error("expected an active-low input reading high to be clear")
+	}
+
+	door := inject.NewSensor("door")
+	door.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"open": 1.0}, nil
+	}
+	path, _ := parseReadingPath("open")
+	in = &interlockInput{cfg: InterlockConfig{Name: "door"}, sensor: door, path: path}
+	if tripped, err := in.tripped(context.Background()); err != nil || !tripped {
+		t.Errorf("expected a non-zero reading to trip, got %v, %v", tripped, err)
+	}
+}
+
+func TestController_Interlock(t *testing.T) {
+	t.Run("trip aborts the cycle and latches until reset", func(t *testing.T) {
+		kctrl := newTestController(t)
+		var stops atomic.Int32
+		testArm := inject.NewArm("test-arm")
+		testArm.IsMovingFunc = func(ctx context.Context) (bool, error) { return false, nil }
+		testArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			stops.Add(1)
+			return nil
+		}
+		kctrl.arm = testArm
+		moving := make(chan struct{})
+		pourPrep := inject.NewSwitch("pour-prep")
+		pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			close(moving)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		kctrl.pourPrep = pourPrep
+		kctrl.interlocks = []*interlockInput{{cfg: InterlockConfig{Name: "door"}}}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := kctrl.handleExecuteCycle(context.Background())
+			errCh <- err
+		}()
+		<-moving
+		kctrl.setInterlockInput(context.Background(), "door", true, "")
+
+		select {
+		case err := <-errCh:
+			if err == nil {
+				t.Error("expected the in-flight cycle to be aborted")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("in-flight cycle was not cancelled")
+		}
+		if stops.Load() != 1 {
+			t.Errorf("expected arm.Stop once, got %d", stops.Load())
+		}
+
+		state := kctrl.GetState()
+		if state["state"] != "interlocked" || state["interlock"] != "door" {
+			t.Errorf("expected interlocked state, got %v", state)
+		}
+		if _, err := kctrl.handleExecuteCycle(context.Background()); err == nil || !strings.Contains(err.Error(), "interlocked") {
+			t.Errorf("expected execute_cycle to be refused, got %v", err)
+		}
+		if _, err := kctrl.handleReset(); err == nil {
+			t.Error("expected reset to fail while the input is tripped")
+		}
+
+		kctrl.setInterlockInput(context.Background(), "door", false, "")
+		if state := kctrl.GetState(); state["state"] != "interlocked" {
+			t.Errorf("expected the interlock to stay latched after clearing, got %v", state["state"])
+		}
+		if _, err := kctrl.handleReset(); err != nil {
+			t.Fatalf("reset failed: %v", err)
+		}
+
+		stopped, _ := kctrl.handleStop(context.Background())
+		events := stopped["interlock_events"].([]interface{})
+		var kinds []string
+		for _, e := range events {
+			kinds = append(kinds, e.(map[string]interface{})["event"].(string))
+		}
+		if strings.Join(kinds, ",") != "tripped,cleared,reset" {
+			t.Errorf("expected tripped, cleared, reset events, got %v", kinds)
+		}
+	})
+
+	t.Run("monitor reads board pins", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		deps, cfg := testDeps()
+		var high atomic.Bool
+		pin := &inject.GPIOPin{}
+		pin.GetFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			return high.Load(), nil
+		}
+		b := inject.NewBoard("pi")
+		b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) { return pin, nil }
+		deps[resource.NewName(board.API, "pi")] = b
+		cfg.Interlocks = []InterlockConfig{{Name: "estop", Board: "pi", Pin: "11"}}
+		cfg.InterlockPollMs = 5
+
+		ctrl, err := NewController(context.Background(), deps, resource.NewName(resource.APINamespaceRDK.WithServiceType("generic"), "test"), cfg, logger)
+		if err != nil {
+			t.Fatalf("NewController failed: %v", err)
+		}
+		defer ctrl.Close(context.Background())
+		kctrl := ctrl.(*kettleCycleTestController)
+
+		high.Store(true)
+		deadline := time.Now().Add(2 * time.Second)
+		for !kctrl.interlocked() && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if !kctrl.interlocked() {
+			t.Fatal("expected the monitor to latch the interlock")
+		}
+		if _, err := kctrl.handleStart(context.Background(), nil); err == nil {
+			t.Error("expected start to be refused while interlocked")
+		}
+	})
+}
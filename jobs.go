@@ -0,0 +1,211 @@
+package kettlecycletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const defaultJobRetentionSec = 600
+
+// Job states reported by job_status.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+// job is an execute_cycle or execute_cycles call running in the background.
+type job struct {
+	id         string
+	command    string
+	total      int // cycles requested
+	state      string
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	results    []interface{} // one result per finished cycle
+	err        string
+	cancel     context.CancelFunc
+}
+
+func (j *job) finished() bool {
+	return j.state == jobSucceeded || j.state == jobFailed || j.state == jobCancelled
+}
+
+func (j *job) statusMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"job_id":     j.id,
+		"command":    j.command,
+		"state":      j.state,
+		"completed":  len(j.results),
+		"total":      j.total,
+		"created_at": j.createdAt.Format(time.RFC3339Nano),
+		"error":      j.err,
+	}
+	if !j.startedAt.IsZero() {
+		m["started_at"] = j.startedAt.Format(time.RFC3339Nano)
+	}
+	if !j.finishedAt.IsZero() {
+		m["finished_at"] = j.finishedAt.Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+// startJob checks the manual policy now, so a rejected command fails the
+// call rather than the job, then runs count cycles in the background.
+func (s *kettleCycleTestController) startJob(command string, count int) (map[string]interface{}, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("%s: count must be at least 1", command)
+	}
+	if err := s.checkManual(command); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(s.cancelCtx)
+	s.mu.Lock()
+	s.pruneJobsLocked(time.Now())
+	s.jobSeq++
+	j := &job{
+		id:        fmt.Sprintf("job-%d", s.jobSeq),
+		command:   command,
+		total:     count,
+		state:     jobQueued,
+		createdAt: time.Now(),
+		cancel:    cancel,
+	}
+	s.jobs[j.id] = j
+	status := j.statusMap()
+	s.mu.Unlock()
+
+	go s.runJob(ctx, j)
+	return status, nil
+}
+
+// runJob executes the job's cycles one at a time, each through the arm
+// executor, stopping at the first error, fault, or cancellation.
+func (s *kettleCycleTestController) runJob(ctx context.Context, j *job) {
+	defer j.cancel()
+	var runErr error
+	for i := 0; i < j.total; i++ {
+		var result map[string]interface{}
+		result, runErr = s.runManual(ctx, j.command, func(ctx context.Context) (map[string]interface{}, error) {
+			s.mu.Lock()
+			if j.state == jobQueued {
+				j.state = jobRunning
+				j.startedAt = time.Now()
+			}
+			s.mu.Unlock()
+			return s.handleManualCycle(ctx)
+		})
+		if runErr != nil {
+			break
+		}
+		s.mu.Lock()
+		j.results = append(j.results, result)
+		s.mu.Unlock()
+		if result["status"] != "completed" {
+			runErr = fmt.Errorf("cycle %d of %d %s: %v", i+1, j.total, result["status"], result["fault"])
+			break
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.finishedAt = time.Now()
+	switch {
+	case runErr == nil:
+		j.state = jobSucceeded
+	case errors.Is(ctx.Err(), context.Canceled):
+		j.state = jobCancelled
+		j.err = runErr.Error()
+	default:
+		j.state = jobFailed
+		j.err = runErr.Error()
+	}
+	s.logger.Infof("%s %s %s after %d of %d cycles", j.command, j.id, j.state, len(j.results), j.total)
+}
+
+// pruneJobsLocked forgets finished jobs older than the retention time.
+func (s *kettleCycleTestController) pruneJobsLocked(now time.Time) {
+	retention := time.Duration(s.cfg.JobRetentionSec) * time.Second
+	if retention <= 0 {
+		retention = defaultJobRetentionSec * time.Second
+	}
+	for id, j := range s.jobs {
+		if j.finished() && now.Sub(j.finishedAt) > retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// lookupJobLocked finds the job named by the command's job_id.
+func (s *kettleCycleTestController) lookupJobLocked(cmd map[string]interface{}) (*job, error) {
+	id, ok := cmd["job_id"].(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("job_id is required")
+	}
+	s.pruneJobsLocked(time.Now())
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown or expired job %q", id)
+	}
+	return j, nil
+}
+
+func (s *kettleCycleTestController) handleExecuteCycles(cmd map[string]interface{}) (map[string]interface{}, error) {
+	count, ok := toFloat64(cmd["count"])
+	if !ok {
+		return nil, fmt.Errorf("execute_cycles requires a 'count'")
+	}
+	return s.startJob("execute_cycles", int(count))
+}
+
+func (s *kettleCycleTestController) handleJobStatus(cmd map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupJobLocked(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return j.statusMap(), nil
+}
+
+// handleJobResult returns a finished job's cycle results; a single
+// execute_cycle job also returns its one result as "result".
+func (s *kettleCycleTestController) handleJobResult(cmd map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.lookupJobLocked(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if !j.finished() {
+		return nil, fmt.Errorf("job %s is still %s", j.id, j.state)
+	}
+	result := j.statusMap()
+	result["results"] = append([]interface{}{}, j.results...)
+	if j.command == "execute_cycle" && len(j.results) == 1 {
+		result["result"] = j.results[0]
+	}
+	return result, nil
+}
+
+// handleCancelJob cancels a queued or running job. A running cycle ends at
+// its next cancellation point, as with abort, but the arm is not stopped.
+func (s *kettleCycleTestController) handleCancelJob(cmd map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	j, err := s.lookupJobLocked(cmd)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	j.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return j.statusMap(), nil
+}
@@ -0,0 +1,93 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func waitJob(t *testing.T, kctrl *kettleCycleTestController, jobID string, until func(status map[string]interface{}) bool) map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		status, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "job_status", "job_id": jobID})
+		if err != nil {
+			t.Fatalf("job_status failed: %v", err)
+		}
+		if until(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s never reached the expected state, last %v", jobID, status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestController_AsyncJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("async execute_cycle returns a job", func(t *testing.T) {
+		kctrl := newTestController(t)
+		resp, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "execute_cycle", "async": true})
+		if err != nil {
+			t.Fatalf("execute_cycle failed: %v", err)
+		}
+		jobID := resp["job_id"].(string)
+		if resp["state"] != jobQueued {
+			t.Errorf("expected the job queued on return, got %v", resp)
+		}
+		if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "job_result", "job_id": jobID}); err == nil {
+			t.Error("expected job_result to refuse an unfinished job")
+		}
+
+		waitJob(t, kctrl, jobID, func(s map[string]interface{}) bool { return s["state"] == jobSucceeded })
+		result, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "job_result", "job_id": jobID})
+		if err != nil {
+			t.Fatalf("job_result failed: %v", err)
+		}
+		if cycle := result["result"].(map[string]interface{}); cycle["status"] != "completed" {
+			t.Errorf("expected the completed cycle, got %v", result)
+		}
+	})
+
+	t.Run("execute_cycles can be cancelled between cycles", func(t *testing.T) {
+		kctrl := newTestController(t)
+		resp, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "execute_cycles", "count": 5})
+		if err != nil {
+			t.Fatalf("execute_cycles failed: %v", err)
+		}
+		jobID := resp["job_id"].(string)
+		waitJob(t, kctrl, jobID, func(s map[string]interface{}) bool { return s["completed"] == 1 })
+
+		if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "cancel_job", "job_id": jobID}); err != nil {
+			t.Fatalf("cancel_job failed: %v", err)
+		}
+		status := waitJob(t, kctrl, jobID, func(s map[string]interface{}) bool { return s["state"] == jobCancelled })
+		if completed := status["completed"].(int); completed >= 5 {
+			t.Errorf("expected the batch cut short, got %d cycles", completed)
+		}
+		if owner, _ := kctrl.executor.state(); owner != "" {
+			t.Errorf("expected the arm released, held by %q", owner)
+		}
+	})
+
+	t.Run("bad requests and expired jobs", func(t *testing.T) {
+		kctrl := newTestController(t)
+		if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "execute_cycles", "count": 0}); err == nil {
+			t.Error("expected error for a zero count")
+		}
+		if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "job_status", "job_id": "job-99"}); err == nil {
+			t.Error("expected error for an unknown job")
+		}
+
+		kctrl.mu.Lock()
+		kctrl.jobs["job-1"] = &job{id: "job-1", state: jobSucceeded, finishedAt: time.Now()}
+		kctrl.pruneJobsLocked(time.Now().Add(defaultJobRetentionSec*time.Second + time.Second))
+		_, kept := kctrl.jobs["job-1"]
+		kctrl.mu.Unlock()
+		if kept {
+			t.Error("expected the finished job pruned after the retention time")
+		}
+	})
+}
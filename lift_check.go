@@ -0,0 +1,47 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+)
+
+// faultLiftFailed is the fault reason when the load cell still carries the
+// kettle after the arm reached pour-prep.
+const faultLiftFailed = "lift_failed"
+
+// LiftCheckConfig enables a post-lift check that the kettle left the load cell.
+type LiftCheckConfig struct {
+	MaxResidual float64 `json:"max_residual"`      // force above this at pour-prep means the kettle was not lifted
+	Samples     int     `json:"samples,omitempty"` // reads averaged per check (default: 5)
+}
+
+func (cfg *LiftCheckConfig) validate() error {
+	if cfg.MaxResidual <= 0 {
+		return fmt.Errorf("max_residual must be positive")
+	}
+	if cfg.Samples < 0 {
+		return fmt.Errorf("samples must not be negative")
+	}
+	return nil
+}
+
+// checkLift reads the load cell once the arm has settled at pour-prep and
+// records the residual force in the result. It reports whether the kettle
+// left the fixture.
+func (s *kettleCycleTestController) checkLift(ctx context.Context, result map[string]interface{}) (bool, error) {
+	cfg := s.cfg.LiftCheck
+	samples := cfg.Samples
+	if samples == 0 {
+		samples = 5
+	}
+	residual, err := s.readForce(ctx, samples)
+	if err != nil {
+		return false, fmt.Errorf("reading lift residual: %w", err)
+	}
+	result["lift_residual"] = residual
+	if residual <= cfg.MaxResidual {
+		return true, nil
+	}
+	s.logger.Warnf("residual force %.2f at pour-prep exceeds %.2f", residual, cfg.MaxResidual)
+	return false, nil
+}
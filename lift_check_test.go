@@ -0,0 +1,80 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestController_LiftCheck(t *testing.T) {
+	t.Run("config requires force_sensor and a threshold", func(t *testing.T) {
+		cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p",
+			LiftCheck: &LiftCheckConfig{MaxResidual: 5}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for lift_check without force_sensor")
+		}
+		cfg.ForceSensor = "force"
+		if _, _, err := cfg.Validate("test"); err != nil {
+			t.Errorf("expected valid config, got %v", err)
+		}
+		cfg.LiftCheck.MaxResidual = 0
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error without max_residual")
+		}
+	})
+
+	t.Run("residual force fails the cycle", func(t *testing.T) {
+		kctrl := newTestController(t)
+		force := newWeighingForceSensor(150)
+		var commands []string
+		read := force.DoFunc
+		force.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			commands = append(commands, cmd["command"].(string))
+			return read(ctx, cmd)
+		}
+		kctrl.forceSensor = force
+		kctrl.cfg.LiftCheck = &LiftCheckConfig{MaxResidual: 5}
+		returned := false
+		resting := inject.NewSwitch("resting")
+		resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			returned = true
+			return nil
+		}
+		kctrl.resting = resting
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "faulted" || result["fault"] != faultLiftFailed || result["lift_residual"] != 150.0 {
+			t.Errorf("expected lift_failed with residual, got %v", result)
+		}
+		if !returned {
+			t.Error("expected the arm to return to resting")
+		}
+		if len(commands) != 1 || commands[0] != "read_force" {
+			t.Errorf("expected no capture after a failed lift, got %v", commands)
+		}
+		if state := kctrl.GetState(); state["fault"] != faultLiftFailed {
+			t.Errorf("expected lift_failed trial fault, got %v", state["fault"])
+		}
+	})
+
+	t.Run("lifted kettle completes the cycle", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.forceSensor = newWeighingForceSensor(0.8)
+		kctrl.cfg.LiftCheck = &LiftCheckConfig{MaxResidual: 5}
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "completed" || result["lift_residual"] != 0.8 {
+			t.Errorf("expected completed cycle with residual, got %v", result)
+		}
+	})
+}
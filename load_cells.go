@@ -0,0 +1,150 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// LoadCellConfig describes one load cell under the kettle platform.
+// X and Y are its position in any consistent unit (e.g. mm from the platform center).
+type LoadCellConfig struct {
+	Name         string   `json:"name"`
+	X            float64  `json:"x"`
+	Y            float64  `json:"y"`
+	ForceKey     string   `json:"force_key,omitempty"`
+	ForceKeys    []string `json:"force_keys,omitempty"`
+	ForceCombine string   `json:"force_combine,omitempty"`
+}
+
+// PlatformPoint is a position on the platform, in the same units as the load cell positions.
+type PlatformPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func (p PlatformPoint) toMap() map[string]interface{} {
+	return map[string]interface{}{"x": p.X, "y": p.Y}
+}
+
+func (p PlatformPoint) distanceTo(o PlatformPoint) float64 {
+	return math.Hypot(p.X-o.X, p.Y-o.Y)
+}
+
+// cellReader is implemented by readers that report each load cell separately.
+type cellReader interface {
+	ReadCells(ctx context.Context) ([]float64, error)
+}
+
+// multiCellForceReader reads several load cells; ReadForce returns their total.
+type multiCellForceReader struct {
+	names   []string
+	readers []forceReader
+}
+
+func (m *multiCellForceReader) ReadCells(ctx context.Context) ([]float64, error) {
+	forces := make([]float64, len(m.readers))
+	for i, r := range m.readers {
+		f, err := r.ReadForce(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load cell %q: %w", m.names[i], err)
+		}
+		forces[i] = f
+	}
+	return forces, nil
+}
+
+func (m *multiCellForceReader) ReadForce(ctx context.Context) (float64, error) {
+	forces, err := m.ReadCells(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return sumForces(forces), nil
+}
+
+func sumForces(forces []float64) float64 {
+	var total float64
+	for _, f := range forces {
+		total += f
+	}
+	return total
+}
+
+// centerOfPressure returns the force-weighted mean of the cell positions.
+// It is undefined (ok=false) when the total force is not positive.
+func centerOfPressure(cells []LoadCellConfig, forces []float64) (PlatformPoint, bool) {
+	total := sumForces(forces)
+	if total <= 0 || len(forces) != len(cells) {
+		return PlatformPoint{}, false
+	}
+	var p PlatformPoint
+	for i, c := range cells {
+		p.X += c.X * forces[i]
+		p.Y += c.Y * forces[i]
+	}
+	p.X /= total
+	p.Y /= total
+	return p, true
+}
+
+// cellCentroid is the default expected landing center: the mean of the cell positions.
+func cellCentroid(cells []LoadCellConfig) PlatformPoint {
+	var p PlatformPoint
+	if len(cells) == 0 {
+		return p
+	}
+	for _, c := range cells {
+		p.X += c.X
+		p.Y += c.Y
+	}
+	p.X /= float64(len(cells))
+	p.Y /= float64(len(cells))
+	return p
+}
+
+// landingSummary describes where the kettle landed during a capture.
+type landingSummary struct {
+	peakCoP   PlatformPoint // center of pressure at peak total force
+	offset    float64       // distance of peakCoP from the expected center
+	maxOffset float64       // largest center-of-pressure offset over the capture
+	offCenter bool          // offset exceeded the configured tolerance
+}
+
+// summarizeLanding evaluates the center of pressure over a capture.
+// ok is false when no sample had positive total force.
+func summarizeLanding(cells []LoadCellConfig, center PlatformPoint, tolerance float64, cellSamples [][]float64) (landingSummary, bool) {
+	var summary landingSummary
+	peak := math.Inf(-1)
+	found := false
+	for _, forces := range cellSamples {
+		cop, ok := centerOfPressure(cells, forces)
+		if !ok {
+			continue
+		}
+		found = true
+		offset := cop.distanceTo(center)
+		if offset > summary.maxOffset {
+			summary.maxOffset = offset
+		}
+		if total := sumForces(forces); total > peak {
+			peak = total
+			summary.peakCoP = cop
+			summary.offset = offset
+		}
+	}
+	if !found {
+		return landingSummary{}, false
+	}
+	summary.offCenter = tolerance > 0 && summary.offset > tolerance
+	return summary, true
+}
+
+func (cfg *LoadCellConfig) validate() error {
+	if cfg.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := parseForcePaths(cfg.ForceKey, cfg.ForceKeys, cfg.ForceCombine); err != nil {
+		return err
+	}
+	return nil
+}
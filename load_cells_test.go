@@ -0,0 +1,183 @@
+package kettlecycletest
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// squareCells is a 200x200 platform with a cell at each corner.
+func squareCells() []LoadCellConfig {
+	return []LoadCellConfig{
+		{Name: "front-left", X: -100, Y: 100},
+		{Name: "front-right", X: 100, Y: 100},
+		{Name: "back-left", X: -100, Y: -100},
+		{Name: "back-right", X: 100, Y: -100},
+	}
+}
+
+func TestForceSensorConfig_LoadCells(t *testing.T) {
+	t.Run("valid load_cells returns every cell as dependency", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCells: squareCells(), OffCenterTolerance: 20}
+		deps, _, err := cfg.Validate("test")
+		if err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+		if len(deps) != 4 {
+			t.Errorf("expected 4 dependencies, got %v", deps)
+		}
+	})
+
+	t.Run("load_cell and load_cells are exclusive", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc", LoadCells: squareCells()}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error when both load_cell and load_cells set")
+		}
+	})
+
+	t.Run("errors name the failing cell", func(t *testing.T) {
+		cells := squareCells()
+		cells[2].ForceKey = "a..b"
+		cfg := &ForceSensorConfig{LoadCells: cells}
+		_, _, err := cfg.Validate("test")
+		if err == nil || !strings.Contains(err.Error(), "load_cells[2]") {
+			t.Errorf("expected error naming load_cells[2], got %v", err)
+		}
+
+		cells = squareCells()
+		cells[3].Name = "front-left"
+		cfg = &ForceSensorConfig{LoadCells: cells}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for duplicate load cell names")
+		}
+	})
+
+	t.Run("off_center_tolerance requires load_cells", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc", OffCenterTolerance: 10}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for off_center_tolerance with single load_cell")
+		}
+	})
+}
+
+func TestCenterOfPressure(t *testing.T) {
+	cells := squareCells()
+
+	cop, ok := centerOfPressure(cells, []float64{10, 10, 10, 10})
+	if !ok || cop.X != 0 || cop.Y != 0 {
+		t.Errorf("even load: got %v (ok=%v), want origin", cop, ok)
+	}
+
+	cop, ok = centerOfPressure(cells, []float64{0, 30, 0, 10})
+	if !ok || cop.X != 100 || cop.Y != 50 {
+		t.Errorf("right-heavy load: got %v (ok=%v), want (100, 50)", cop, ok)
+	}
+
+	if _, ok := centerOfPressure(cells, []float64{0, 0, 0, 0}); ok {
+		t.Error("expected undefined center of pressure with no load")
+	}
+}
+
+func TestSummarizeLanding(t *testing.T) {
+	cells := squareCells()
+	samples := [][]float64{
+		{0, 0, 0, 0},
+		{10, 30, 10, 30}, // peak, shifted right by 50
+		{10, 10, 10, 10},
+	}
+
+	summary, ok := summarizeLanding(cells, PlatformPoint{}, 20, samples)
+	if !ok {
+		t.Fatal("expected landing summary")
+	}
+	if summary.peakCoP.X != 50 || summary.peakCoP.Y != 0 {
+		t.Errorf("peak center of pressure = %v, want (50, 0)", summary.peakCoP)
+	}
+	if math.Abs(summary.offset-50) > 1e-9 || !summary.offCenter {
+		t.Errorf("expected off-center landing at offset 50, got %+v", summary)
+	}
+
+	summary, _ = summarizeLanding(cells, PlatformPoint{}, 0, samples)
+	if summary.offCenter {
+		t.Error("tolerance 0 should disable the off-center alarm")
+	}
+
+	if _, ok := summarizeLanding(cells, PlatformPoint{}, 20, [][]float64{{0, 0, 0, 0}}); ok {
+		t.Error("expected no summary without load")
+	}
+}
+
+func TestForceSensor_MultiCellCapture(t *testing.T) {
+	cells := squareCells()
+	multi := &multiCellForceReader{}
+	for i, cell := range cells {
+		force := 20.0
+		if i%2 == 1 {
+			force = 60.0 // right side heavier
+		}
+		s := inject.NewSensor(cell.Name)
+		s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"value": force}, nil
+		}
+		p, _ := parseReadingPath("value")
+		multi.names = append(multi.names, cell.Name)
+		multi.readers = append(multi.readers, newSensorForceReader(s, []readingPath{p}, ""))
+	}
+
+	fs := &forceSensor{
+		name:               resource.NewName(resource.APINamespaceRDK.WithComponentType("sensor"), "test"),
+		logger:             logging.NewTestLogger(t),
+		reader:             multi,
+		sampleRateHz:       100,
+		bufferSize:         100,
+		zeroThreshold:      5.0,
+		captureTimeout:     10 * time.Second,
+		ring:               newSampleRing(100, len(cells)),
+		cells:              cells,
+		expectedCenter:     cellCentroid(cells),
+		offCenterTolerance: 25,
+	}
+	fs.startSampling()
+	defer fs.Close(context.Background())
+
+	fs.handleStartCapture(map[string]interface{}{})
+	time.Sleep(50 * time.Millisecond)
+
+	readings, _ := fs.Readings(context.Background(), nil)
+	if readings["total_force"] != 160.0 {
+		t.Errorf("expected total_force=160, got %v", readings["total_force"])
+	}
+	profiles, ok := readings["cell_samples"].(map[string]interface{})
+	if !ok || len(profiles) != 4 {
+		t.Fatalf("expected 4 per-cell profiles, got %v", readings["cell_samples"])
+	}
+	copX := readings["cop_x"].([]interface{})
+	if len(copX) == 0 || copX[0] != 50.0 {
+		t.Errorf("expected cop_x series starting at 50, got %v", copX)
+	}
+
+	result, err := fs.handleEndCapture()
+	if err != nil {
+		t.Fatalf("handleEndCapture failed: %v", err)
+	}
+	if result["off_center"] != true {
+		t.Errorf("expected off_center=true, got %v", result["off_center"])
+	}
+	if flags := stringList(result["impact_flags"]); !contains(flags, flagOffCenter) {
+		t.Errorf("expected off_center in impact_flags, got %v", flags)
+	}
+	faults, _ := evaluateImpactRules(map[string]string{flagOffCenter: impactActionFault}, result)
+	if !contains(faults, flagOffCenter) {
+		t.Errorf("expected an off_center rule to fault, got %v", faults)
+	}
+	cellMax := result["cell_max_force"].(map[string]interface{})
+	if cellMax["front-right"] != 60.0 {
+		t.Errorf("expected front-right max 60, got %v", cellMax["front-right"])
+	}
+}
@@ -0,0 +1,239 @@
+package kettlecycletest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Standard trial metadata fields, given at the top level of the start command
+const (
+	metaSpecimenID  = "specimen_id"
+	metaKettleModel = "kettle_model"
+	metaOperator    = "operator"
+	metaTestPlan    = "test_plan"
+	metaNotes       = "notes"
+	metaCustom      = "custom"
+)
+
+var metadataFields = []string{metaSpecimenID, metaKettleModel, metaOperator, metaTestPlan, metaNotes}
+
+// taggedMetadataFields are added to image tags; notes and custom values are
+// free text and stay out of tags.
+var taggedMetadataFields = []string{metaSpecimenID, metaKettleModel, metaOperator, metaTestPlan}
+
+// captureMetadata holds the identifying trial fields a sensor was given with
+// start_capture, so its readings name the specimen and operator.
+type captureMetadata map[string]string
+
+// parseCaptureMetadata reads the identifying fields from a start_capture
+// command. Missing or non-string fields are left empty.
+func parseCaptureMetadata(cmd map[string]interface{}) captureMetadata {
+	m := captureMetadata{}
+	for _, key := range taggedMetadataFields {
+		if v, ok := cmd[key].(string); ok && v != "" {
+			m[key] = v
+		}
+	}
+	return m
+}
+
+// addTo reports every identifying field, empty when not given, so each one
+// is its own column in captured readings.
+func (m captureMetadata) addTo(out map[string]interface{}) {
+	for _, key := range taggedMetadataFields {
+		out[key] = m[key]
+	}
+}
+
+// TrialMetadataConfig is the schema start metadata is checked against.
+type TrialMetadataConfig struct {
+	// Standard fields or custom keys every trial must name
+	Required []string `json:"required,omitempty"`
+	// Custom keys allowed; any custom key is accepted when empty
+	CustomKeys []string `json:"custom_keys,omitempty"`
+	// Permitted values per standard field or custom key
+	AllowedValues map[string][]string `json:"allowed_values,omitempty"`
+}
+
+func (c *TrialMetadataConfig) validate() error {
+	known := func(key string) bool {
+		return isMetadataField(key) || len(c.CustomKeys) == 0 || contains(c.CustomKeys, key)
+	}
+	for _, key := range c.CustomKeys {
+		if key == "" || isMetadataField(key) {
+			return fmt.Errorf("custom_keys: %q is not a valid custom key", key)
+		}
+	}
+	for _, key := range c.Required {
+		if key == "" || !known(key) {
+			return fmt.Errorf("required: unknown field %q", key)
+		}
+	}
+	for key, values := range c.AllowedValues {
+		if !known(key) {
+			return fmt.Errorf("allowed_values: unknown field %q", key)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("allowed_values: %s lists no values", key)
+		}
+	}
+	return nil
+}
+
+// check refuses metadata missing a required field, using an unlisted custom
+// key, or holding a value outside allowed_values.
+func (c *TrialMetadataConfig) check(m trialMetadata) error {
+	for _, key := range c.Required {
+		if m.get(key) == "" {
+			return fmt.Errorf("trial metadata requires %q", key)
+		}
+	}
+	if len(c.CustomKeys) > 0 {
+		for key := range m.custom {
+			if !contains(c.CustomKeys, key) {
+				return fmt.Errorf("unknown custom metadata key %q (must be one of %v)", key, c.CustomKeys)
+			}
+		}
+	}
+	for key, values := range c.AllowedValues {
+		if v := m.get(key); v != "" && !contains(values, v) {
+			return fmt.Errorf("trial metadata %s %q is not one of %v", key, v, values)
+		}
+	}
+	return nil
+}
+
+// trialMetadata describes what a trial tests and who ran it.
+type trialMetadata struct {
+	fields map[string]string // standard fields that were given
+	custom map[string]string
+}
+
+// parseTrialMetadata reads the standard fields and the "custom" object from
+// a start command. Custom values may be strings, numbers or booleans.
+func parseTrialMetadata(cmd map[string]interface{}) (trialMetadata, error) {
+	m := trialMetadata{fields: map[string]string{}, custom: map[string]string{}}
+	for _, key := range metadataFields {
+		raw, ok := cmd[key]
+		if !ok {
+			continue
+		}
+		v, ok := raw.(string)
+		if !ok {
+			return m, fmt.Errorf("%s must be a string", key)
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			m.fields[key] = v
+		}
+	}
+	raw, ok := cmd[metaCustom]
+	if !ok || raw == nil {
+		return m, nil
+	}
+	custom, ok := raw.(map[string]interface{})
+	if !ok {
+		return m, fmt.Errorf("%s must be an object of key/values", metaCustom)
+	}
+	for key, v := range custom {
+		if key == "" || isMetadataField(key) {
+			return m, fmt.Errorf("%s: %q is not a valid custom key", metaCustom, key)
+		}
+		switch v.(type) {
+		case string, float64, int, bool:
+			if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+				m.custom[key] = s
+			}
+		default:
+			return m, fmt.Errorf("%s: %s must be a string, number or boolean", metaCustom, key)
+		}
+	}
+	return m, nil
+}
+
+// get returns a standard field, or a custom value by key.
+func (m trialMetadata) get(key string) string {
+	if isMetadataField(key) {
+		return m.fields[key]
+	}
+	return m.custom[key]
+}
+
+// toMap reports every standard field, empty when not given, and the custom
+// key/values.
+func (m trialMetadata) toMap() map[string]interface{} {
+	out := make(map[string]interface{}, len(metadataFields)+1)
+	for _, key := range metadataFields {
+		out[key] = m.fields[key]
+	}
+	custom := make(map[string]interface{}, len(m.custom))
+	for k, v := range m.custom {
+		custom[k] = v
+	}
+	out[metaCustom] = custom
+	return out
+}
+
+// tags returns "<field>:<value>" image tags for the identifying fields.
+func (m trialMetadata) tags() []string {
+	var tags []string
+	for _, key := range taggedMetadataFields {
+		if v := m.fields[key]; v != "" {
+			tags = append(tags, fmt.Sprintf("%s:%s", key, v))
+		}
+	}
+	return tags
+}
+
+// addCaptureFields adds the identifying fields to a sensor's start_capture
+// command.
+func (m trialMetadata) addCaptureFields(cmd map[string]interface{}) {
+	for _, key := range taggedMetadataFields {
+		if v := m.fields[key]; v != "" {
+			cmd[key] = v
+		}
+	}
+}
+
+func (m trialMetadata) String() string {
+	var parts []string
+	for _, key := range metadataFields {
+		if v := m.fields[key]; v != "" && key != metaNotes {
+			parts = append(parts, fmt.Sprintf("%s=%s", key, v))
+		}
+	}
+	keys := make([]string, 0, len(m.custom))
+	for k := range m.custom {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, m.custom[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// addMetadataState reports the standard fields at the top level of status,
+// so each one is its own column in captured readings, and the custom
+// key/values under custom_metadata.
+func addMetadataState(state map[string]interface{}, m trialMetadata) {
+	for k, v := range m.toMap() {
+		if k == metaCustom {
+			k = "custom_metadata"
+		}
+		state[k] = v
+	}
+}
+
+func isMetadataField(key string) bool {
+	return contains(metadataFields, key)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
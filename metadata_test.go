@@ -0,0 +1,181 @@
+package kettlecycletest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestParseTrialMetadata(t *testing.T) {
+	m, err := parseTrialMetadata(map[string]interface{}{
+		"specimen_id":  " SN-1042 ",
+		"kettle_model": "KX-200",
+		"notes":        "second batch",
+		"custom":       map[string]interface{}{"lot": "A7", "fill_ml": 1500.0},
+	})
+	if err != nil {
+		t.Fatalf("parseTrialMetadata failed: %v", err)
+	}
+	if m.get("specimen_id") != "SN-1042" || m.get("fill_ml") != "1500" {
+		t.Errorf("unexpected metadata %v", m.toMap())
+	}
+	if tags := strings.Join(m.tags(), ","); tags != "specimen_id:SN-1042,kettle_model:KX-200" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	for name, cmd := range map[string]map[string]interface{}{
+		"non-string field":   {"operator": 7.0},
+		"custom not object":  {"custom": "lot=A7"},
+		"nested custom":      {"custom": map[string]interface{}{"lot": []interface{}{"A7"}}},
+		"custom shadows std": {"custom": map[string]interface{}{"operator": "sam"}},
+	} {
+		if _, err := parseTrialMetadata(cmd); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestTrialMetadataConfig(t *testing.T) {
+	cfg := &TrialMetadataConfig{
+		Required:      []string{"specimen_id", "operator", "lot"},
+		CustomKeys:    []string{"lot"},
+		AllowedValues: map[string][]string{"kettle_model": {"KX-200", "KX-300"}},
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if err := (&TrialMetadataConfig{CustomKeys: []string{"lot"}, Required: []string{"batch"}}).validate(); err == nil {
+		t.Error("expected error for a required key that is not allowed")
+	}
+	if err := (&TrialMetadataConfig{AllowedValues: map[string][]string{"operator": {}}}).validate(); err == nil {
+		t.Error("expected error for an empty allowed list")
+	}
+
+	valid := map[string]interface{}{"specimen_id": "SN-1", "operator": "sam", "custom": map[string]interface{}{"lot": "A7"}}
+	check := func(overrides map[string]interface{}) error {
+		cmd := map[string]interface{}{}
+		for k, v := range valid {
+			cmd[k] = v
+		}
+		for k, v := range overrides {
+			cmd[k] = v
+		}
+		m, err := parseTrialMetadata(cmd)
+		if err != nil {
+			t.Fatalf("parseTrialMetadata failed: %v", err)
+		}
+		return cfg.check(m)
+	}
+	if err := check(nil); err != nil {
+		t.Errorf("expected valid metadata to pass, got %v", err)
+	}
+	if err := check(map[string]interface{}{"operator": ""}); err == nil {
+		t.Error("expected error for a missing required field")
+	}
+	if err := check(map[string]interface{}{"custom": map[string]interface{}{"lot": "A7", "batch": "3"}}); err == nil {
+		t.Error("expected error for an unlisted custom key")
+	}
+	if err := check(map[string]interface{}{"kettle_model": "KX-100"}); err == nil {
+		t.Error("expected error for a value outside allowed_values")
+	}
+}
+
+func TestController_StartMetadata(t *testing.T) {
+	ctx := context.Background()
+	kctrl := newTestController(t)
+	kctrl.cfg.TrialMetadata = &TrialMetadataConfig{Required: []string{"specimen_id"}}
+
+	if _, err := kctrl.DoCommand(ctx, map[string]interface{}{"command": "start"}); err == nil {
+		t.Fatal("expected start to refuse metadata missing specimen_id")
+	}
+	if state := kctrl.GetState(); state["specimen_id"] != "" {
+		t.Errorf("expected empty metadata while idle, got %v", state["specimen_id"])
+	}
+
+	resp, err := kctrl.DoCommand(ctx, map[string]interface{}{
+		"command":     "start",
+		"specimen_id": "SN-1042",
+		"operator":    "sam",
+		"custom":      map[string]interface{}{"lot": "A7"},
+	})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if resp["metadata"].(map[string]interface{})["operator"] != "sam" {
+		t.Errorf("expected metadata echoed, got %v", resp["metadata"])
+	}
+
+	state := kctrl.GetState()
+	if state["specimen_id"] != "SN-1042" || state["custom_metadata"].(map[string]interface{})["lot"] != "A7" {
+		t.Errorf("expected metadata in status, got %v %v", state["specimen_id"], state["custom_metadata"])
+	}
+	events, _ := kctrl.DoCommand(ctx, map[string]interface{}{"command": "events"})
+	started := events["events"].([]interface{})[0].(map[string]interface{})
+	if started["kind"] != eventTrialStarted || started["specimen_id"] != "SN-1042" {
+		t.Errorf("expected the specimen on the trial_started event, got %v", started)
+	}
+
+	result, err := kctrl.handleStop(ctx)
+	if err != nil {
+		t.Fatalf("handleStop failed: %v", err)
+	}
+	if result["metadata"].(map[string]interface{})["specimen_id"] != "SN-1042" {
+		t.Errorf("expected metadata in the trial summary, got %v", result["metadata"])
+	}
+
+	// Each cycle's record and result carry the trial's metadata
+	metadata, _ := parseTrialMetadata(map[string]interface{}{"specimen_id": "SN-2001"})
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{}), metadata: metadata}
+	kctrl.mu.Unlock()
+	cycle, err := kctrl.handleExecuteCycle(ctx)
+	if err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	if cycle["metadata"].(map[string]interface{})["specimen_id"] != "SN-2001" {
+		t.Errorf("expected metadata in the cycle result, got %v", cycle["metadata"])
+	}
+	history := kctrl.history.query("test-trial", 0)
+	record := history[0].(map[string]interface{})
+	if record["metadata"].(map[string]interface{})["specimen_id"] != "SN-2001" {
+		t.Errorf("expected metadata on the cycle record, got %v", record["metadata"])
+	}
+}
+
+func TestController_MetadataToSensors(t *testing.T) {
+	kctrl := newTestController(t)
+	metadata, _ := parseTrialMetadata(map[string]interface{}{"specimen_id": "SN-2001", "operator": "sam", "notes": "free text"})
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{}), metadata: metadata}
+
+	var forceStart, telemetryStart map[string]interface{}
+	force := inject.NewSensor("force")
+	force.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "start_capture" {
+			forceStart = cmd
+		}
+		return map[string]interface{}{}, nil
+	}
+	kctrl.forceSensor = force
+	telemetry := inject.NewSensor("telemetry")
+	telemetry.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "start_capture" {
+			telemetryStart = cmd
+		}
+		return map[string]interface{}{}, nil
+	}
+	kctrl.telemetry = telemetry
+
+	if _, err := kctrl.handleExecuteCycle(context.Background()); err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	for name, cmd := range map[string]map[string]interface{}{"force sensor": forceStart, "arm telemetry": telemetryStart} {
+		if cmd["specimen_id"] != "SN-2001" || cmd["operator"] != "sam" {
+			t.Errorf("expected specimen and operator in the %s start_capture, got %v", name, cmd)
+		}
+		if _, ok := cmd["notes"]; ok {
+			t.Errorf("expected notes kept out of the %s start_capture, got %v", name, cmd)
+		}
+	}
+}
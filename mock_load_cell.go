@@ -0,0 +1,264 @@
+package kettlecycletest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var MockLoadCell = resource.NewModel("viamdemo", "kettle-cycle-test", "mock-load-cell")
+
+func init() {
+	resource.RegisterComponent(sensor.API, MockLoadCell,
+		resource.Registration[sensor.Sensor, *MockLoadCellConfig]{
+			Constructor: newMockLoadCell,
+		},
+	)
+}
+
+// MockLoadCellConfig configures a virtual load cell under the kettle.
+// Contact follows the arm's end-effector height when arm is set,
+// otherwise it is driven by the set_contact DoCommand.
+type MockLoadCellConfig struct {
+	Arm             string             `json:"arm,omitempty"`               // optional: kettle is down when the arm is low
+	ContactHeightMm *float64           `json:"contact_height_mm,omitempty"` // required with arm: end-effector Z at or below this is contact (0 is a valid height)
+	SampleRateHz    int                `json:"sample_rate_hz,omitempty"`    // rate the simulated signal changes at (default: 50)
+	Profile         *MockProfileConfig `json:"profile,omitempty"`           // optional: shape, noise, drift and faults
+}
+
+func (cfg *MockLoadCellConfig) Validate(path string) ([]string, []string, error) {
+	if cfg.SampleRateHz < 0 {
+		return nil, nil, fmt.Errorf("%s: sample_rate_hz must not be negative", path)
+	}
+	if cfg.Profile != nil {
+		if err := cfg.Profile.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: profile: %w", path, err)
+		}
+	}
+	if cfg.Arm == "" {
+		if cfg.ContactHeightMm != nil {
+			return nil, nil, fmt.Errorf("%s: contact_height_mm requires arm", path)
+		}
+		return nil, nil, nil
+	}
+	if cfg.ContactHeightMm == nil {
+		return nil, nil, fmt.Errorf("%s: arm requires contact_height_mm", path)
+	}
+	return []string{cfg.Arm}, nil, nil
+}
+
+type mockLoadCell struct {
+	resource.AlwaysRebuild
+
+	name   resource.Name
+	logger logging.Logger
+
+	curve           *mockForceReader
+	arm             arm.Arm // optional, may be nil
+	contactHeightMm float64
+	sampleRateHz    int
+
+	mu           sync.Mutex
+	inContact    bool
+	contactSince time.Time
+	followArm    bool // false while set_contact overrides the arm
+	cycle        int  // cycle_count from set_contact, 0 counts contacts locally
+}
+
+func newMockLoadCell(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
+	conf, err := resource.NativeConfig[*MockLoadCellConfig](rawConf)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := conf.SampleRateHz
+	if sampleRate <= 0 {
+		sampleRate = 50
+	}
+
+	var profile MockProfileConfig
+	if conf.Profile != nil {
+		profile = *conf.Profile
+	}
+
+	lc := newSimulatedLoadCell(rawConf.ResourceName(), profile, sampleRate, logger)
+	lc.contactHeightMm = valueOr(conf.ContactHeightMm, 0)
+
+	if conf.Arm != "" {
+		lc.arm, err = arm.FromProvider(deps, conf.Arm)
+		if err != nil {
+			return nil, fmt.Errorf("getting arm: %w", err)
+		}
+		lc.followArm = true
+		logger.Infof("mock-load-cell following arm %q (contact at or below z=%.1f mm)", conf.Arm, lc.contactHeightMm)
+	}
+
+	return lc, nil
+}
+
+// newSimulatedLoadCell builds a mock load cell without an arm. The force
+// sensor's use_mock_curve reads one, putting the kettle down for each capture.
+func newSimulatedLoadCell(name resource.Name, profile MockProfileConfig, sampleRate int, logger logging.Logger) *mockLoadCell {
+	return &mockLoadCell{
+		name:         name,
+		logger:       logger,
+		curve:        newMockForceReaderWithProfile(profile, sampleRate),
+		sampleRateHz: sampleRate,
+	}
+}
+
+func (lc *mockLoadCell) Name() resource.Name {
+	return lc.name
+}
+
+// Readings reports the simulated force. The curve is evaluated from the time
+// since contact, so polling rate does not change the profile.
+func (lc *mockLoadCell) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	if err := lc.followArmContact(ctx); err != nil {
+		return nil, err
+	}
+
+	lc.mu.Lock()
+	inContact := lc.inContact
+	n := int(time.Since(lc.contactSince).Seconds()*float64(lc.sampleRateHz)) + 1
+	lc.mu.Unlock()
+
+	force, err := lc.curve.ReadForceAt(n)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"value":      force,
+		"in_contact": inContact,
+	}, nil
+}
+
+// followArmContact derives contact from the arm's end-effector height.
+func (lc *mockLoadCell) followArmContact(ctx context.Context) error {
+	lc.mu.Lock()
+	follow := lc.followArm && lc.arm != nil
+	lc.mu.Unlock()
+	if !follow {
+		return nil
+	}
+
+	pose, err := lc.arm.EndPosition(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("getting arm end position: %w", err)
+	}
+	lc.setContact(pose.Point().Z <= lc.contactHeightMm)
+	return nil
+}
+
+// setContact records a contact transition and restarts the curve on touchdown.
+func (lc *mockLoadCell) setContact(inContact bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if inContact == lc.inContact {
+		return
+	}
+	lc.inContact = inContact
+	if inContact {
+		lc.contactSince = time.Now()
+		lc.curve.captureStarted(lc.cycle)
+	} else {
+		lc.curve.captureEnded()
+	}
+}
+
+// captureStarted puts the kettle down for a capture of cycleCount.
+func (lc *mockLoadCell) captureStarted(cycleCount int) {
+	lc.mu.Lock()
+	lc.followArm = false
+	lc.cycle = cycleCount
+	lc.mu.Unlock()
+	lc.setContact(true)
+}
+
+// captureEnded lifts the kettle at the end of a capture.
+func (lc *mockLoadCell) captureEnded() {
+	lc.setContact(false)
+}
+
+func (lc *mockLoadCell) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	command, ok := cmd["command"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'command' field")
+	}
+
+	switch command {
+	case "set_contact":
+		return lc.handleSetContact(cmd)
+	case "set_mock_profile":
+		return lc.handleSetMockProfile(cmd)
+	default:
+		return nil, fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// handleSetContact puts the kettle down or lifts it. "follow_arm": true hands
+// contact back to the arm; an explicit "in_contact" overrides the arm.
+func (lc *mockLoadCell) handleSetContact(cmd map[string]interface{}) (map[string]interface{}, error) {
+	if follow, ok := cmd["follow_arm"].(bool); ok && follow {
+		if lc.arm == nil {
+			return nil, fmt.Errorf("follow_arm requires arm to be configured")
+		}
+		lc.mu.Lock()
+		lc.followArm = true
+		lc.mu.Unlock()
+		return map[string]interface{}{"status": "following_arm"}, nil
+	}
+
+	inContact, ok := cmd["in_contact"].(bool)
+	if !ok {
+		return nil, fmt.Errorf("set_contact requires 'in_contact' (bool) or 'follow_arm': true")
+	}
+
+	lc.mu.Lock()
+	lc.followArm = false
+	if cycleCount, ok := cmd["cycle_count"].(float64); ok {
+		lc.cycle = int(cycleCount)
+	} else if cycleCount, ok := cmd["cycle_count"].(int); ok {
+		lc.cycle = cycleCount
+	}
+	lc.mu.Unlock()
+
+	lc.setContact(inContact)
+	return map[string]interface{}{"status": "ok", "in_contact": inContact}, nil
+}
+
+// handleSetMockProfile changes the mock curve at runtime, including mid-trial.
+// Fields in "profile" are applied over the current profile.
+func (lc *mockLoadCell) handleSetMockProfile(cmd map[string]interface{}) (map[string]interface{}, error) {
+	update, ok := cmd["profile"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("set_mock_profile requires a 'profile' object")
+	}
+	profile, err := lc.curve.SetProfile(update)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock profile: %w", err)
+	}
+	lc.logger.Infof("mock profile updated: %+v", profile)
+
+	raw, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	var profileMap map[string]interface{}
+	if err := json.Unmarshal(raw, &profileMap); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"status": "ok", "profile": profileMap}, nil
+}
+
+func (lc *mockLoadCell) Close(context.Context) error {
+	return nil
+}
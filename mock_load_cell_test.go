@@ -0,0 +1,191 @@
+package kettlecycletest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// heightArm is a fake arm whose end effector sits at a settable height.
+// Methods other than Name and EndPosition are not implemented.
+type heightArm struct {
+	arm.Arm
+	mu sync.Mutex
+	z  float64
+}
+
+func (a *heightArm) Name() resource.Name { return arm.Named("arm") }
+
+func (a *heightArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return spatialmath.NewPoseFromPoint(r3.Vector{Z: a.z}), nil
+}
+
+func (a *heightArm) setZ(z float64) {
+	a.mu.Lock()
+	a.z = z
+	a.mu.Unlock()
+}
+
+func newTestMockLoadCell(t *testing.T, conf *MockLoadCellConfig, deps resource.Dependencies) *mockLoadCell {
+	t.Helper()
+	rawConf := resource.Config{
+		Name:                "mock-cell",
+		API:                 sensor.API,
+		Model:               MockLoadCell,
+		ConvertedAttributes: conf,
+	}
+	s, err := newMockLoadCell(context.Background(), deps, rawConf, logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("newMockLoadCell failed: %v", err)
+	}
+	return s.(*mockLoadCell)
+}
+
+func TestMockLoadCellConfig(t *testing.T) {
+	t.Run("no dependencies without arm", func(t *testing.T) {
+		deps, _, err := (&MockLoadCellConfig{}).Validate("test")
+		if err != nil || len(deps) != 0 {
+			t.Errorf("expected no deps and no error, got %v, %v", deps, err)
+		}
+	})
+
+	t.Run("arm requires contact_height_mm", func(t *testing.T) {
+		if _, _, err := (&MockLoadCellConfig{Arm: "arm"}).Validate("test"); err == nil {
+			t.Error("expected error for arm without contact_height_mm")
+		}
+		deps, _, err := (&MockLoadCellConfig{Arm: "arm", ContactHeightMm: ptr(120.0)}).Validate("test")
+		if err != nil || len(deps) != 1 || deps[0] != "arm" {
+			t.Errorf("expected [arm], got %v, %v", deps, err)
+		}
+		// A table at height 0 is a valid contact height
+		if _, _, err := (&MockLoadCellConfig{Arm: "arm", ContactHeightMm: ptr(0.0)}).Validate("test"); err != nil {
+			t.Errorf("expected contact_height_mm 0 to be accepted, got %v", err)
+		}
+	})
+
+	t.Run("validates profile", func(t *testing.T) {
+		cfg := &MockLoadCellConfig{Profile: &MockProfileConfig{Shape: "square"}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for invalid profile shape")
+		}
+	})
+}
+
+func TestMockLoadCell_SetContact(t *testing.T) {
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{}, resource.Dependencies{})
+	ctx := context.Background()
+
+	readings, err := lc.Readings(ctx, nil)
+	if err != nil {
+		t.Fatalf("Readings failed: %v", err)
+	}
+	if readings["value"] != mockLiftedForce || readings["in_contact"] != false {
+		t.Errorf("expected lifted reading, got %v", readings)
+	}
+
+	if _, err := lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "in_contact": true}); err != nil {
+		t.Fatalf("set_contact failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	readings, _ = lc.Readings(ctx, nil)
+	if readings["in_contact"] != true || readings["value"].(float64) < 50 {
+		t.Errorf("expected contact force on the ramp, got %v", readings)
+	}
+
+	if _, err := lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact"}); err == nil {
+		t.Error("expected error without in_contact")
+	}
+	if _, err := lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "follow_arm": true}); err == nil {
+		t.Error("expected error for follow_arm without an arm")
+	}
+}
+
+func TestMockLoadCell_FollowsArm(t *testing.T) {
+	testArm := &heightArm{z: 300}
+	deps := resource.Dependencies{arm.Named("arm"): testArm}
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{Arm: "arm", ContactHeightMm: ptr(120.0)}, deps)
+	ctx := context.Background()
+
+	readings, _ := lc.Readings(ctx, nil)
+	if readings["in_contact"] != false {
+		t.Errorf("expected no contact with arm raised, got %v", readings)
+	}
+
+	testArm.setZ(110)
+	readings, _ = lc.Readings(ctx, nil)
+	if readings["in_contact"] != true {
+		t.Errorf("expected contact with arm lowered, got %v", readings)
+	}
+
+	// Manual override ignores the arm until follow_arm is restored
+	lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "in_contact": false})
+	readings, _ = lc.Readings(ctx, nil)
+	if readings["in_contact"] != false {
+		t.Errorf("expected override to lift the kettle, got %v", readings)
+	}
+	lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "follow_arm": true})
+	readings, _ = lc.Readings(ctx, nil)
+	if readings["in_contact"] != true {
+		t.Errorf("expected contact to follow arm again, got %v", readings)
+	}
+}
+
+func TestForceSensor_WrapsMockLoadCell(t *testing.T) {
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{}, resource.Dependencies{})
+	ctx := context.Background()
+
+	rawConf := resource.Config{
+		Name:                "force",
+		API:                 sensor.API,
+		Model:               ForceSensor,
+		ConvertedAttributes: &ForceSensorConfig{LoadCell: "mock-cell", SampleRateHz: 100},
+	}
+	deps := resource.Dependencies{sensor.Named("mock-cell"): lc}
+	s, err := newForceSensor(ctx, deps, rawConf, logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("newForceSensor failed: %v", err)
+	}
+	defer s.Close(ctx)
+	fs := s.(*forceSensor)
+	if _, ok := fs.reader.(*sensorForceReader); !ok {
+		t.Fatalf("expected the plain sensorForceReader path, got %T", fs.reader)
+	}
+
+	fs.handleStartCapture(map[string]interface{}{})
+	lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "in_contact": true})
+	time.Sleep(100 * time.Millisecond)
+	lc.DoCommand(ctx, map[string]interface{}{"command": "set_contact", "in_contact": false})
+
+	result, err := fs.handleEndCapture()
+	if err != nil {
+		t.Fatalf("handleEndCapture failed: %v", err)
+	}
+	if result["sample_count"].(int) == 0 || result["max_force"].(float64) < 50 {
+		t.Errorf("expected force profile from mock load cell, got %v", result)
+	}
+}
+
+func TestMockLoadCell_ContactAtHeightZero(t *testing.T) {
+	testArm := &heightArm{z: 50}
+	deps := resource.Dependencies{arm.Named("arm"): testArm}
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{Arm: "arm", ContactHeightMm: ptr(0.0)}, deps)
+	ctx := context.Background()
+
+	if readings, _ := lc.Readings(ctx, nil); readings["in_contact"] != false {
+		t.Errorf("expected no contact above the table, got %v", readings)
+	}
+	testArm.setZ(0)
+	if readings, _ := lc.Readings(ctx, nil); readings["in_contact"] != true {
+		t.Errorf("expected contact at the table, got %v", readings)
+	}
+}
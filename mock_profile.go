@@ -0,0 +1,300 @@
+package kettlecycletest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+const (
+	mockShapeRamp      = "ramp"
+	mockShapeOvershoot = "overshoot"
+
+	// mockLiftedForce is what the mock reports while the kettle is in the air.
+	mockLiftedForce = 0.5
+)
+
+// MockProfileConfig scripts the mock force curve. Curve fields left out take
+// defaults that reproduce the original ramp from 50 to 200 over one second at
+// 50 Hz; they are pointers so that an explicit 0 is kept.
+type MockProfileConfig struct {
+	Shape        string   `json:"shape,omitempty"`          // "ramp" (default) or "overshoot" (overshoot-and-settle)
+	StartForce   *float64 `json:"start_force,omitempty"`    // force at first contact (default: 50)
+	PeakForce    *float64 `json:"peak_force,omitempty"`     // steady-state force (default: 200)
+	RiseTimeMs   *int     `json:"rise_time_ms,omitempty"`   // time from contact to peak (default: 1000)
+	Overshoot    *float64 `json:"overshoot,omitempty"`      // overshoot fraction of peak (default: 0.3)
+	SettleTimeMs *int     `json:"settle_time_ms,omitempty"` // time for overshoot to settle (default: 500)
+
+	NoiseStdDev   float64 `json:"noise_std_dev,omitempty"`   // Gaussian noise added to every reading
+	Seed          int64   `json:"seed,omitempty"`            // noise seed, for repeatable runs
+	DriftPerCycle float64 `json:"drift_per_cycle,omitempty"` // added to peak_force each cycle
+
+	// Fault injection: every N cycles (0 disables)
+	DropoutEvery   int     `json:"dropout_every,omitempty"`    // readings drop to zero mid-contact
+	DropoutSamples int     `json:"dropout_samples,omitempty"`  // length of a dropout (default: 5)
+	SpikeEvery     int     `json:"spike_every,omitempty"`      // single spike mid-contact
+	SpikeForce     float64 `json:"spike_force,omitempty"`      // spike magnitude (default: 2x peak_force)
+	ReadErrorEvery int     `json:"read_error_every,omitempty"` // one failed read mid-contact
+	FaultAtSample  *int    `json:"fault_at_sample,omitempty"`  // contact sample where faults start (default: half the rise)
+}
+
+func (cfg *MockProfileConfig) validate() error {
+	switch cfg.Shape {
+	case "", mockShapeRamp, mockShapeOvershoot:
+	default:
+		return fmt.Errorf("shape %q is invalid (must be %q or %q)", cfg.Shape, mockShapeRamp, mockShapeOvershoot)
+	}
+	p := cfg.withDefaults()
+	nonNegative := map[string]float64{
+		"start_force":      p.startForce,
+		"peak_force":       p.peakForce,
+		"rise_time_ms":     float64(p.riseTimeMs),
+		"overshoot":        p.overshoot,
+		"settle_time_ms":   float64(p.settleTimeMs),
+		"noise_std_dev":    cfg.NoiseStdDev,
+		"dropout_every":    float64(cfg.DropoutEvery),
+		"dropout_samples":  float64(cfg.DropoutSamples),
+		"spike_every":      float64(cfg.SpikeEvery),
+		"read_error_every": float64(cfg.ReadErrorEvery),
+		"fault_at_sample":  float64(valueOr(cfg.FaultAtSample, 0)),
+	}
+	for name, v := range nonNegative {
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// mockCurve is a profile with defaults applied to the fields left out.
+type mockCurve struct {
+	MockProfileConfig
+	startForce    float64
+	peakForce     float64
+	riseTimeMs    int
+	overshoot     float64
+	settleTimeMs  int
+	faultAtSample int // -1: half the rise
+}
+
+// withDefaults resolves the curve fields, keeping explicit zeros.
+func (cfg MockProfileConfig) withDefaults() mockCurve {
+	p := mockCurve{
+		MockProfileConfig: cfg,
+		startForce:        valueOr(cfg.StartForce, 50),
+		peakForce:         valueOr(cfg.PeakForce, 200),
+		riseTimeMs:        valueOr(cfg.RiseTimeMs, 1000),
+		overshoot:         valueOr(cfg.Overshoot, 0.3),
+		settleTimeMs:      valueOr(cfg.SettleTimeMs, 500),
+		faultAtSample:     valueOr(cfg.FaultAtSample, -1),
+	}
+	if p.Shape == "" {
+		p.Shape = mockShapeRamp
+	}
+	if p.DropoutSamples == 0 {
+		p.DropoutSamples = 5
+	}
+	return p
+}
+
+// clone copies the profile so that decoding into it leaves the original's
+// pointed-to values alone.
+func (cfg MockProfileConfig) clone() MockProfileConfig {
+	c := cfg
+	c.StartForce = copyPtr(cfg.StartForce)
+	c.PeakForce = copyPtr(cfg.PeakForce)
+	c.RiseTimeMs = copyPtr(cfg.RiseTimeMs)
+	c.Overshoot = copyPtr(cfg.Overshoot)
+	c.SettleTimeMs = copyPtr(cfg.SettleTimeMs)
+	c.FaultAtSample = copyPtr(cfg.FaultAtSample)
+	return c
+}
+
+func valueOr[T any](v *T, def T) T {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// mockForceReader simulates realistic force profile: zeros while lifted, curve on contact
+type mockForceReader struct {
+	sampleRateHz int
+
+	mu           sync.Mutex
+	profile      MockProfileConfig // as configured, before defaults
+	rng          *rand.Rand
+	inContact    bool
+	contactCount int
+	cycle        int // cycle being played, from start_capture or counted locally
+
+	// Spikes and read errors fire once per contact, on the first read at or
+	// after the fault sample, so a reader slower than the mock rate still sees them
+	spikeFired     bool
+	readErrorFired bool
+}
+
+func newMockForceReader() *mockForceReader {
+	return newMockForceReaderWithProfile(MockProfileConfig{}, 50)
+}
+
+func newMockForceReaderWithProfile(profile MockProfileConfig, sampleRateHz int) *mockForceReader {
+	return &mockForceReader{
+		sampleRateHz: sampleRateHz,
+		profile:      profile,
+		rng:          rand.New(rand.NewSource(profile.Seed)),
+	}
+}
+
+func (m *mockForceReader) ReadForce(ctx context.Context) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.inContact {
+		// Kettle is lifted - return near-zero
+		return mockLiftedForce + m.noise(), nil
+	}
+
+	m.contactCount++
+	return m.sampleForce(m.contactCount)
+}
+
+// ReadForceAt returns the force for the nth sample since contact, for callers
+// that derive the sample index from elapsed time rather than call count.
+func (m *mockForceReader) ReadForceAt(n int) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.inContact {
+		return mockLiftedForce + m.noise(), nil
+	}
+	return m.sampleForce(n)
+}
+
+// sampleForce applies faults and noise to the curve. Callers must hold m.mu.
+func (m *mockForceReader) sampleForce(n int) (float64, error) {
+	p := m.profile.withDefaults()
+
+	faultAt := p.faultAtSample
+	if faultAt < 0 {
+		faultAt = m.samples(p.riseTimeMs) / 2
+	}
+	if isFaultCycle(m.cycle, p.ReadErrorEvery) && n >= faultAt && !m.readErrorFired {
+		m.readErrorFired = true
+		return 0, fmt.Errorf("mock load cell: injected read error (cycle %d)", m.cycle)
+	}
+	if isFaultCycle(m.cycle, p.DropoutEvery) && n >= faultAt && n < faultAt+p.DropoutSamples {
+		return 0, nil
+	}
+	if isFaultCycle(m.cycle, p.SpikeEvery) && n >= faultAt && !m.spikeFired {
+		m.spikeFired = true
+		spike := p.SpikeForce
+		if spike == 0 {
+			spike = 2 * p.peakForce
+		}
+		return spike, nil
+	}
+
+	return m.curve(p, n) + m.noise(), nil
+}
+
+// curve returns the noiseless force for the nth sample since contact.
+func (m *mockForceReader) curve(p mockCurve, n int) float64 {
+	peak := p.peakForce + p.DriftPerCycle*float64(max(m.cycle-1, 0))
+	rise := m.samples(p.riseTimeMs)
+
+	target := peak
+	if p.Shape == mockShapeOvershoot {
+		target = peak * (1 + p.overshoot)
+	}
+	if n < rise {
+		return p.startForce + (target-p.startForce)*float64(n)/float64(rise)
+	}
+	if p.Shape == mockShapeRamp {
+		return peak
+	}
+
+	// Damped oscillation around peak, two periods over the settle time
+	settle := float64(m.samples(p.settleTimeMs))
+	t := float64(n - rise)
+	return peak * (1 + p.overshoot*math.Exp(-4*t/settle)*math.Cos(4*math.Pi*t/settle))
+}
+
+// samples converts a duration in ms to a sample count at the configured rate.
+func (m *mockForceReader) samples(ms int) int {
+	n := ms * m.sampleRateHz / 1000
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (m *mockForceReader) noise() float64 {
+	if m.profile.NoiseStdDev == 0 {
+		return 0
+	}
+	return m.rng.NormFloat64() * m.profile.NoiseStdDev
+}
+
+func isFaultCycle(cycle, every int) bool {
+	return every > 0 && cycle > 0 && cycle%every == 0
+}
+
+func (m *mockForceReader) captureStarted(cycleCount int) {
+	m.mu.Lock()
+	if cycleCount > 0 {
+		m.cycle = cycleCount
+	} else {
+		m.cycle++
+	}
+	m.mu.Unlock()
+	m.SetContact(true)
+}
+
+func (m *mockForceReader) captureEnded() { m.SetContact(false) }
+
+func (m *mockForceReader) SetContact(inContact bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inContact = inContact
+	if inContact {
+		m.contactCount = 0
+		m.spikeFired = false
+		m.readErrorFired = false
+	}
+}
+
+// SetProfile applies the fields present in update over the current profile.
+// A changed seed restarts the noise sequence.
+func (m *mockForceReader) SetProfile(update map[string]interface{}) (MockProfileConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	raw, err := json.Marshal(update)
+	if err != nil {
+		return MockProfileConfig{}, fmt.Errorf("encoding profile: %w", err)
+	}
+	next := m.profile.clone()
+	if err := json.Unmarshal(raw, &next); err != nil {
+		return MockProfileConfig{}, fmt.Errorf("decoding profile: %w", err)
+	}
+	if err := next.validate(); err != nil {
+		return MockProfileConfig{}, err
+	}
+	if _, ok := update["seed"]; ok {
+		m.rng = rand.New(rand.NewSource(next.Seed))
+	}
+	m.profile = next
+	return next, nil
+}
@@ -0,0 +1,330 @@
+package kettlecycletest
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// playContact starts a capture for cycle and reads n samples.
+func playContact(m *mockForceReader, cycle, n int) ([]float64, []error) {
+	m.captureStarted(cycle)
+	defer m.captureEnded()
+	forces := make([]float64, n)
+	errs := make([]error, n)
+	for i := range forces {
+		forces[i], errs[i] = m.ReadForce(context.Background())
+	}
+	return forces, errs
+}
+
+func TestMockForceReader_DefaultMatchesOriginalRamp(t *testing.T) {
+	m := newMockForceReader()
+	if f, _ := m.ReadForce(context.Background()); f != mockLiftedForce {
+		t.Errorf("expected %v while lifted, got %v", mockLiftedForce, f)
+	}
+
+	forces, _ := playContact(m, 0, 60)
+	if forces[0] != 53 || forces[9] != 80 || forces[55] != 200 {
+		t.Errorf("unexpected default ramp: first=%v tenth=%v held=%v", forces[0], forces[9], forces[55])
+	}
+}
+
+func TestMockForceReader_Overshoot(t *testing.T) {
+	m := newMockForceReaderWithProfile(MockProfileConfig{
+		Shape:        mockShapeOvershoot,
+		PeakForce:    ptr(100.0),
+		RiseTimeMs:   ptr(200),
+		Overshoot:    ptr(0.5),
+		SettleTimeMs: ptr(400),
+	}, 50)
+
+	forces, _ := playContact(m, 1, 100)
+	maxForce := 0.0
+	for _, f := range forces {
+		maxForce = math.Max(maxForce, f)
+	}
+	if maxForce < 145 || maxForce > 150 {
+		t.Errorf("expected overshoot to about 150, got max %v", maxForce)
+	}
+	if last := forces[len(forces)-1]; math.Abs(last-100) > 1 {
+		t.Errorf("expected force to settle at 100, got %v", last)
+	}
+}
+
+func TestMockForceReader_ExplicitZeros(t *testing.T) {
+	// Zero start force, zero overshoot on an overshoot shape and a 0 ms rise
+	// are kept rather than replaced by defaults
+	m := newMockForceReaderWithProfile(MockProfileConfig{
+		Shape:      mockShapeOvershoot,
+		StartForce: ptr(0.0),
+		PeakForce:  ptr(100.0),
+		Overshoot:  ptr(0.0),
+	}, 50)
+	forces, _ := playContact(m, 1, 80)
+	if forces[0] != 2 {
+		t.Errorf("expected the ramp to start from 0, got %v", forces[0])
+	}
+	for _, f := range forces {
+		if f > 100 {
+			t.Fatalf("expected no overshoot, got %v", f)
+		}
+	}
+
+	m = newMockForceReaderWithProfile(MockProfileConfig{RiseTimeMs: ptr(0)}, 50)
+	if forces, _ := playContact(m, 1, 2); forces[1] != 200 {
+		t.Errorf("expected the peak at once with a 0 ms rise, got %v", forces)
+	}
+
+	// set_mock_profile keeps an explicit zero too
+	m = newMockForceReader()
+	if _, err := m.SetProfile(map[string]interface{}{"start_force": 0.0}); err != nil {
+		t.Fatalf("SetProfile failed: %v", err)
+	}
+	if forces, _ := playContact(m, 1, 1); forces[0] != 4 {
+		t.Errorf("expected the ramp to start from 0 after set_mock_profile, got %v", forces[0])
+	}
+}
+
+func TestMockForceReader_NoiseIsSeeded(t *testing.T) {
+	profile := MockProfileConfig{NoiseStdDev: 2, Seed: 42}
+	a, _ := playContact(newMockForceReaderWithProfile(profile, 50), 1, 20)
+	b, _ := playContact(newMockForceReaderWithProfile(profile, 50), 1, 20)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed produced different noise at sample %d: %v vs %v", i, a[i], b[i])
+		}
+	}
+
+	clean, _ := playContact(newMockForceReader(), 1, 20)
+	if a[5] == clean[5] {
+		t.Error("expected noise to perturb the curve")
+	}
+}
+
+func TestMockForceReader_Drift(t *testing.T) {
+	m := newMockForceReaderWithProfile(MockProfileConfig{DriftPerCycle: -10}, 50)
+	first, _ := playContact(m, 1, 60)
+	tenth, _ := playContact(m, 10, 60)
+	if first[59] != 200 || tenth[59] != 110 {
+		t.Errorf("expected peak 200 on cycle 1 and 110 on cycle 10, got %v and %v", first[59], tenth[59])
+	}
+}
+
+func TestMockForceReader_FaultInjection(t *testing.T) {
+	profile := MockProfileConfig{
+		DropoutEvery:   2,
+		DropoutSamples: 3,
+		SpikeEvery:     3,
+		SpikeForce:     999,
+		ReadErrorEvery: 5,
+		FaultAtSample:  ptr(10),
+	}
+	m := newMockForceReaderWithProfile(profile, 50)
+
+	clean, errs := playContact(m, 1, 20)
+	for i, err := range errs {
+		if err != nil || clean[i] == 0 || clean[i] == 999 {
+			t.Fatalf("cycle 1 should be fault-free, sample %d = %v (err %v)", i, clean[i], err)
+		}
+	}
+
+	dropout, _ := playContact(m, 2, 20)
+	if dropout[9] != 0 || dropout[11] != 0 || dropout[12] == 0 {
+		t.Errorf("expected 3-sample dropout from sample 10 on cycle 2, got %v", dropout[8:13])
+	}
+
+	spike, _ := playContact(m, 3, 20)
+	if spike[9] != 999 {
+		t.Errorf("expected spike at sample 10 on cycle 3, got %v", spike[9])
+	}
+
+	_, errs = playContact(m, 5, 20)
+	if errs[9] == nil {
+		t.Error("expected injected read error at sample 10 on cycle 5")
+	}
+}
+
+func TestMockForceReader_FaultsAtCoarseReadRate(t *testing.T) {
+	profile := MockProfileConfig{
+		SpikeEvery:     1,
+		SpikeForce:     999,
+		ReadErrorEvery: 2,
+		FaultAtSample:  ptr(10),
+	}
+	m := newMockForceReaderWithProfile(profile, 50)
+
+	// Read every third sample, so the reads skip sample 10 itself
+	readCoarse := func(cycle int) (spikes, readErrs int) {
+		m.captureStarted(cycle)
+		defer m.captureEnded()
+		for n := 2; n <= 40; n += 3 {
+			f, err := m.ReadForceAt(n)
+			if err != nil {
+				readErrs++
+			} else if f == 999 {
+				spikes++
+			}
+		}
+		return spikes, readErrs
+	}
+
+	if spikes, readErrs := readCoarse(1); spikes != 1 || readErrs != 0 {
+		t.Errorf("cycle 1: expected 1 spike and no read error, got %d spikes, %d read errors", spikes, readErrs)
+	}
+	if spikes, readErrs := readCoarse(2); spikes != 1 || readErrs != 1 {
+		t.Errorf("cycle 2: expected 1 spike and 1 read error, got %d spikes, %d read errors", spikes, readErrs)
+	}
+}
+
+func TestMockForceReader_SetProfile(t *testing.T) {
+	m := newMockForceReaderWithProfile(MockProfileConfig{PeakForce: ptr(150.0)}, 50)
+
+	profile, err := m.SetProfile(map[string]interface{}{"spike_every": 1.0})
+	if err != nil {
+		t.Fatalf("SetProfile failed: %v", err)
+	}
+	if *profile.PeakForce != 150 || profile.SpikeEvery != 1 {
+		t.Errorf("expected update merged over current profile, got %+v", profile)
+	}
+
+	if _, err := m.SetProfile(map[string]interface{}{"shape": "sawtooth"}); err == nil {
+		t.Error("expected error for invalid shape")
+	}
+	if m.profile.SpikeEvery != 1 {
+		t.Error("invalid update should leave the profile unchanged")
+	}
+}
+
+func TestMockLoadCell_SetMockProfileCommand(t *testing.T) {
+	lc := newTestMockLoadCell(t, &MockLoadCellConfig{}, resource.Dependencies{})
+
+	result, err := lc.DoCommand(context.Background(), map[string]interface{}{
+		"command": "set_mock_profile",
+		"profile": map[string]interface{}{"peak_force": 300.0, "shape": "overshoot"},
+	})
+	if err != nil {
+		t.Fatalf("set_mock_profile failed: %v", err)
+	}
+	profile := result["profile"].(map[string]interface{})
+	if profile["peak_force"] != 300.0 || profile["shape"] != "overshoot" {
+		t.Errorf("unexpected profile in result: %v", profile)
+	}
+
+	if _, err := lc.DoCommand(context.Background(), map[string]interface{}{
+		"command": "set_mock_profile",
+		"profile": map[string]interface{}{"noise_std_dev": -1.0},
+	}); err == nil {
+		t.Error("expected error for negative noise_std_dev")
+	}
+}
+
+func TestForceSensorConfig_MockProfile(t *testing.T) {
+	cfg := &ForceSensorConfig{LoadCell: "adc", MockProfile: &MockProfileConfig{PeakForce: ptr(100.0)}}
+	if _, _, err := cfg.Validate("test"); err == nil || !strings.Contains(err.Error(), "use_mock_curve") {
+		t.Errorf("expected mock_profile without use_mock_curve to fail, got %v", err)
+	}
+
+	cfg = &ForceSensorConfig{UseMockCurve: true, MockProfile: &MockProfileConfig{Shape: "square"}}
+	if _, _, err := cfg.Validate("test"); err == nil {
+		t.Error("expected invalid mock_profile to fail")
+	}
+
+	cfg = &ForceSensorConfig{UseMockCurve: true, MockProfile: &MockProfileConfig{PeakForce: ptr(100.0)}}
+	if _, _, err := cfg.Validate("test"); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+}
+
+func TestForceSensor_SetMockProfileForwards(t *testing.T) {
+	setPeak := map[string]interface{}{
+		"command": "set_mock_profile",
+		"profile": map[string]interface{}{"peak_force": 300.0},
+	}
+
+	t.Run("to the use_mock_curve cell", func(t *testing.T) {
+		rawConf := resource.Config{
+			Name:                "force",
+			API:                 sensor.API,
+			Model:               ForceSensor,
+			ConvertedAttributes: &ForceSensorConfig{UseMockCurve: true, MockProfile: &MockProfileConfig{PeakForce: ptr(150.0)}},
+		}
+		s, err := newForceSensor(context.Background(), resource.Dependencies{}, rawConf, logging.NewTestLogger(t))
+		if err != nil {
+			t.Fatalf("newForceSensor failed: %v", err)
+		}
+		defer s.Close(context.Background())
+		if got := valueOr(s.(*forceSensor).mock.curve.profile.PeakForce, 0); got != 150 {
+			t.Errorf("expected mock_profile peak_force 150, got %v", got)
+		}
+
+		result, err := s.DoCommand(context.Background(), setPeak)
+		if err != nil {
+			t.Fatalf("set_mock_profile failed: %v", err)
+		}
+		if profile := result["profile"].(map[string]interface{}); profile["peak_force"] != 300.0 {
+			t.Errorf("unexpected profile in result: %v", profile)
+		}
+	})
+
+	t.Run("to a mock-load-cell load_cell", func(t *testing.T) {
+		lc := newTestMockLoadCell(t, &MockLoadCellConfig{}, resource.Dependencies{})
+		fs := newTestForceSensor(t)
+		fs.loadCell = lc
+		if _, err := fs.DoCommand(context.Background(), setPeak); err != nil {
+			t.Fatalf("set_mock_profile failed: %v", err)
+		}
+		if got := valueOr(lc.curve.profile.PeakForce, 0); got != 300 {
+			t.Errorf("expected the wrapped cell's peak_force 300, got %v", got)
+		}
+	})
+
+	t.Run("errors without a mock cell", func(t *testing.T) {
+		fs := newTestForceSensor(t)
+		if _, err := fs.DoCommand(context.Background(), setPeak); err == nil {
+			t.Error("expected set_mock_profile to fail without a wrapped cell")
+		}
+	})
+}
+
+func TestForceSensor_UseMockCurve(t *testing.T) {
+	rawConf := resource.Config{
+		Name:                "force",
+		API:                 sensor.API,
+		Model:               ForceSensor,
+		ConvertedAttributes: &ForceSensorConfig{UseMockCurve: true, SampleRateHz: 200},
+	}
+	s, err := newForceSensor(context.Background(), resource.Dependencies{}, rawConf, logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatalf("newForceSensor failed: %v", err)
+	}
+	fs := s.(*forceSensor)
+	defer fs.Close(context.Background())
+
+	// The internal cell reads only noise until a capture puts the kettle down
+	if f, err := fs.reader.ReadForce(context.Background()); err != nil || f >= fs.zeroThreshold {
+		t.Fatalf("expected noise below %v before capture, got %v, %v", fs.zeroThreshold, f, err)
+	}
+	if _, err := fs.handleStartCapture(map[string]interface{}{}); err != nil {
+		t.Fatalf("start_capture failed: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	result, err := fs.handleEndCapture()
+	if err != nil {
+		t.Fatalf("end_capture failed: %v", err)
+	}
+	if max, _ := result["max_force"].(float64); max <= 0 {
+		t.Errorf("expected the mock curve to produce force, got max_force %v", result["max_force"])
+	}
+	if f, _ := fs.reader.ReadForce(context.Background()); f >= fs.zeroThreshold {
+		t.Errorf("expected noise below %v after capture, got %v", fs.zeroThreshold, f)
+	}
+}
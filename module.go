@@ -15,11 +15,13 @@ import (
 	"go.viam.com/rdk/app"
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/gripper"
 	"go.viam.com/rdk/components/sensor"
 	toggleswitch "go.viam.com/rdk/components/switch"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	generic "go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/vision"
 )
 
 var Controller = resource.NewModel("viamdemo", "kettle-cycle-test", "controller")
@@ -37,12 +39,61 @@ type Config struct {
 	RestingPosition  string `json:"resting_position"`
 	PourPrepPosition string `json:"pour_prep_position"`
 	ForceSensor      string `json:"force_sensor,omitempty"`
+	Gripper          string `json:"gripper,omitempty"`       // grips the handle before lift and releases it after put-down
+	ArmTelemetry     string `json:"arm_telemetry,omitempty"` // arm-telemetry sensor told when each move starts and ends
 
 	// Camera capture settings (Camera, DatasetID, PartID required if Camera is set)
 	// API credentials read from VIAM_API_KEY and VIAM_API_KEY_ID environment variables
 	Camera    string `json:"camera,omitempty"`
 	DatasetID string `json:"dataset_id,omitempty"`
 	PartID    string `json:"part_id,omitempty"`
+
+	// Vision service classifying the handle; preflight checks it answers
+	VisionService string `json:"vision_service,omitempty"`
+
+	// Manual execute_cycle, grip and release while a trial is cycling:
+	// "reject" (default) or "queue" behind the in-flight cycle
+	ManualDuringTrial string `json:"manual_during_trial,omitempty"`
+
+	// Schema for the metadata start accepts: required fields, allowed custom
+	// keys and allowed values
+	TrialMetadata *TrialMetadataConfig `json:"trial_metadata,omitempty"`
+
+	// Preflight checks that only warn instead of refusing start
+	PreflightWarnOnly []string `json:"preflight_warn_only,omitempty"`
+
+	// Action per force sensor impact flag: "fault" stops the trial, "warn"
+	// (default) logs and counts it, "ignore" drops it
+	ImpactRules map[string]string `json:"impact_rules,omitempty"`
+
+	// Force profile drift against a per-trial baseline (requires force_sensor)
+	Drift *DriftConfig `json:"drift,omitempty"`
+
+	// Control charts on max_force, cycle_duration and impulse
+	SPC *SPCConfig `json:"spc,omitempty"`
+
+	// Pre-lift check of the kettle's weight (requires force_sensor)
+	RestingWeight *RestingWeightConfig `json:"resting_weight,omitempty"`
+
+	// Post-lift check that the kettle left the load cell (requires force_sensor)
+	LiftCheck *LiftCheckConfig `json:"lift_check,omitempty"`
+
+	// Safety inputs that stop the arm and latch the controller when tripped
+	Interlocks      []InterlockConfig `json:"interlocks,omitempty"`
+	InterlockPollMs int               `json:"interlock_poll_ms,omitempty"` // how often inputs are read (default: 50)
+
+	// Limits on how long a phase or cycle may run before it is cancelled
+	Watchdog *WatchdogConfig `json:"watchdog,omitempty"`
+
+	// Where the arm should settle at "resting" and "pour_prep"; a miss
+	// beyond tolerance fails the cycle
+	ExpectedPoses map[string]PoseCheckConfig `json:"expected_poses,omitempty"`
+
+	HistorySize  int `json:"history_size,omitempty"`  // cycle records kept for the history command (default: 100)
+	TimingWindow int `json:"timing_window,omitempty"` // completed cycles averaged for phase timing in status (default: 20)
+
+	JobRetentionSec int `json:"job_retention_s,omitempty"` // how long finished async jobs stay queryable (default: 600)
+	EventLogSize    int `json:"event_log_size,omitempty"`  // events kept for the events command (default: 1000)
 }
 
 type trialState struct {
@@ -51,6 +102,39 @@ type trialState struct {
 	startedAt   time.Time
 	lastCycleAt time.Time
 	stopCh      chan struct{}
+	stopOnce    sync.Once
+	loopDone    chan struct{} // closed when the cycle loop exits; nil without a loop
+
+	// A fault stops cycling but keeps the trial until stop is called
+	fault        string
+	warningCount int
+	lastWarning  string
+
+	gripFailures int
+
+	interlockEvents []interlockEvent
+
+	metadata    trialMetadata // specimen, operator and test plan given at start
+	annotations []annotation  // operator notes, oldest first
+
+	// Binary data IDs of the images uploaded for uploadCycle, so a note made
+	// after the upload can still tag them
+	uploadCycle int
+	uploadIDs   []string
+
+	// Resting weight of the first and latest checked cycle, for water loss
+	hasRestingWeight   bool
+	firstRestingWeight float64
+	lastRestingWeight  float64
+
+	drift  *driftMonitor // nil unless drift is configured
+	spc    *spcMonitor   // nil unless spc is configured
+	timing *timingStats  // rolling phase timing of completed cycles
+}
+
+// stop signals the cycle loop to exit; safe to call more than once.
+func (t *trialState) stop() {
+	t.stopOnce.Do(func() { close(t.stopCh) })
 }
 
 func (cfg *Config) Validate(path string) ([]string, []string, error) {
@@ -72,16 +156,120 @@ func (cfg *Config) Validate(path string) ([]string, []string, error) {
 		}
 	}
 
+	if len(cfg.ImpactRules) > 0 {
+		if cfg.ForceSensor == "" {
+			return nil, nil, fmt.Errorf("%s: impact_rules requires force_sensor", path)
+		}
+		if err := validateImpactRules(cfg.ImpactRules); err != nil {
+			return nil, nil, fmt.Errorf("%s: impact_rules: %w", path, err)
+		}
+	}
+
+	if cfg.Drift != nil {
+		if cfg.ForceSensor == "" {
+			return nil, nil, fmt.Errorf("%s: drift requires force_sensor", path)
+		}
+		if err := cfg.Drift.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: drift: %w", path, err)
+		}
+	}
+
+	if cfg.SPC != nil {
+		if err := cfg.SPC.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: spc: %w", path, err)
+		}
+		if cfg.SPC.needsForce() && cfg.ForceSensor == "" {
+			return nil, nil, fmt.Errorf("%s: spc metrics max_force and impulse require force_sensor", path)
+		}
+	}
+	if cfg.RestingWeight != nil {
+		if cfg.ForceSensor == "" {
+			return nil, nil, fmt.Errorf("%s: resting_weight requires force_sensor", path)
+		}
+		if err := cfg.RestingWeight.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: resting_weight: %w", path, err)
+		}
+	}
+	if cfg.LiftCheck != nil {
+		if cfg.ForceSensor == "" {
+			return nil, nil, fmt.Errorf("%s: lift_check requires force_sensor", path)
+		}
+		if err := cfg.LiftCheck.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: lift_check: %w", path, err)
+		}
+	}
+	if cfg.TrialMetadata != nil {
+		if err := cfg.TrialMetadata.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: trial_metadata: %w", path, err)
+		}
+	}
+	if cfg.Watchdog != nil {
+		if err := cfg.Watchdog.validate(); err != nil {
+			return nil, nil, fmt.Errorf("%s: watchdog: %w", path, err)
+		}
+	}
+	if err := validateExpectedPoses(cfg.ExpectedPoses); err != nil {
+		return nil, nil, fmt.Errorf("%s: expected_poses: %w", path, err)
+	}
+	if err := validatePreflightWarnOnly(cfg.PreflightWarnOnly); err != nil {
+		return nil, nil, fmt.Errorf("%s: preflight_warn_only: %w", path, err)
+	}
+	if err := validateInterlocks(cfg.Interlocks); err != nil {
+		return nil, nil, fmt.Errorf("%s: interlocks: %w", path, err)
+	}
+	if cfg.InterlockPollMs < 0 {
+		return nil, nil, fmt.Errorf("%s: interlock_poll_ms must not be negative", path)
+	}
+	if cfg.HistorySize < 0 {
+		return nil, nil, fmt.Errorf("%s: history_size must not be negative", path)
+	}
+	if err := validateManualDuringTrial(cfg.ManualDuringTrial); err != nil {
+		return nil, nil, fmt.Errorf("%s: manual_during_trial: %w", path, err)
+	}
+	if cfg.EventLogSize < 0 {
+		return nil, nil, fmt.Errorf("%s: event_log_size must not be negative", path)
+	}
+	if cfg.JobRetentionSec < 0 {
+		return nil, nil, fmt.Errorf("%s: job_retention_s must not be negative", path)
+	}
+	if cfg.TimingWindow < 0 {
+		return nil, nil, fmt.Errorf("%s: timing_window must not be negative", path)
+	}
+
 	deps := []string{cfg.Arm, cfg.RestingPosition, cfg.PourPrepPosition}
 	if cfg.ForceSensor != "" {
 		deps = append(deps, cfg.ForceSensor)
 	}
+	if cfg.Gripper != "" {
+		deps = append(deps, cfg.Gripper)
+	}
+	if cfg.ArmTelemetry != "" {
+		deps = append(deps, cfg.ArmTelemetry)
+	}
 	if cfg.Camera != "" {
 		deps = append(deps, cfg.Camera)
 	}
+	if cfg.VisionService != "" {
+		deps = append(deps, cfg.VisionService)
+	}
+	for _, in := range cfg.Interlocks {
+		if in.Board != "" {
+			deps = append(deps, in.Board)
+		} else {
+			deps = append(deps, in.Sensor)
+		}
+	}
 	return deps, nil, nil
 }
 
+// datasetClient is the part of the Viam data client the controller uses.
+type datasetClient interface {
+	UploadImageToDatasets(ctx context.Context, partID string, image image.Image, datasetIDs, tags []string,
+		mimeType app.MimeType, opts *app.FileUploadOptions) (string, error)
+	AddTagsToBinaryDataByIDs(ctx context.Context, tags, binaryDataIDs []string) error
+	ListDatasetsByIDs(ctx context.Context, ids []string) ([]*app.Dataset, error)
+}
+
 type kettleCycleTestController struct {
 	resource.AlwaysRebuild
 
@@ -92,20 +280,42 @@ type kettleCycleTestController struct {
 	arm         arm.Arm
 	resting     toggleswitch.Switch
 	pourPrep    toggleswitch.Switch
-	forceSensor sensor.Sensor // optional, may be nil
+	forceSensor sensor.Sensor   // optional, may be nil
+	gripper     gripper.Gripper // optional, may be nil
+	telemetry   sensor.Sensor   // arm-telemetry, optional, may be nil
 
 	// Camera capture (optional)
 	camera     camera.Camera
 	viamClient *app.ViamClient
-	dataClient *app.DataClient
+	dataClient datasetClient
 	datasetID  string
 	partID     string
 
+	vision vision.Service // optional, may be nil
+
 	cancelCtx  context.Context
 	cancelFunc func()
 
-	mu          sync.Mutex
-	activeTrial *trialState
+	mu               sync.Mutex
+	activeTrial      *trialState
+	history          *cycleHistory
+	events           *eventLog
+	lastNotification string
+
+	// Safety interlocks (optional). interlock names the input that latched
+	// the controller; it stays set until reset.
+	interlocks       []*interlockInput
+	interlockTripped map[string]bool
+	interlock        string
+	cycleCancel      context.CancelFunc // cancels the in-flight cycle
+
+	watch *cycleWatch // progress of the in-flight cycle, nil between cycles
+
+	executor armExecutor // serializes everything that moves the arm
+
+	// Background execute_cycle/execute_cycles jobs by job_id
+	jobs   map[string]*job
+	jobSeq int
 }
 
 func newKettleCycleTestController(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (resource.Resource, error) {
@@ -143,10 +353,45 @@ func NewController(ctx context.Context, deps resource.Dependencies, name resourc
 		logger.Infof("controller using force sensor: %s", conf.ForceSensor)
 	}
 
+	var g gripper.Gripper
+	if conf.Gripper != "" {
+		g, err = gripper.FromProvider(deps, conf.Gripper)
+		if err != nil {
+			return nil, fmt.Errorf("getting gripper: %w", err)
+		}
+		logger.Infof("controller using gripper: %s", conf.Gripper)
+	}
+
+	var telemetry sensor.Sensor
+	if conf.ArmTelemetry != "" {
+		telemetry, err = sensor.FromProvider(deps, conf.ArmTelemetry)
+		if err != nil {
+			return nil, fmt.Errorf("getting arm telemetry sensor: %w", err)
+		}
+		logger.Infof("controller using arm telemetry: %s", conf.ArmTelemetry)
+	}
+
+	var vis vision.Service
+	if conf.VisionService != "" {
+		vis, err = vision.FromProvider(deps, conf.VisionService)
+		if err != nil {
+			return nil, fmt.Errorf("getting vision service: %w", err)
+		}
+	}
+
+	interlocks := make([]*interlockInput, 0, len(conf.Interlocks))
+	for _, ic := range conf.Interlocks {
+		in, err := newInterlockInput(deps, ic)
+		if err != nil {
+			return nil, fmt.Errorf("interlock %s: %w", ic.Name, err)
+		}
+		interlocks = append(interlocks, in)
+	}
+
 	// Camera and DataClient initialization (optional)
 	var cam camera.Camera
 	var viamClient *app.ViamClient
-	var dataClient *app.DataClient
+	var dataClient datasetClient
 	if conf.Camera != "" {
 		cam, err = camera.FromProvider(deps, conf.Camera)
 		if err != nil {
@@ -177,6 +422,9 @@ func NewController(ctx context.Context, deps resource.Dependencies, name resourc
 		resting:     resting,
 		pourPrep:    pourPrep,
 		forceSensor: fs,
+		gripper:     g,
+		telemetry:   telemetry,
+		vision:      vis,
 		camera:      cam,
 		viamClient:  viamClient,
 		dataClient:  dataClient,
@@ -184,6 +432,18 @@ func NewController(ctx context.Context, deps resource.Dependencies, name resourc
 		partID:      conf.PartID,
 		cancelCtx:   cancelCtx,
 		cancelFunc:  cancelFunc,
+		history:     newCycleHistory(conf.HistorySize),
+		jobs:        map[string]*job{},
+		events:      newEventLog(conf.EventLogSize),
+
+		interlocks:       interlocks,
+		interlockTripped: map[string]bool{},
+	}
+	if len(interlocks) > 0 {
+		go s.monitorInterlocks(cancelCtx)
+	}
+	if conf.Watchdog != nil {
+		go s.runWatchdog(cancelCtx)
 	}
 	return s, nil
 }
@@ -200,102 +460,504 @@ func (s *kettleCycleTestController) DoCommand(ctx context.Context, cmd map[strin
 
 	switch command {
 	case "execute_cycle":
-		return s.handleExecuteCycle(ctx)
+		if async, _ := cmd["async"].(bool); async {
+			return s.startJob(command, 1)
+		}
+		return s.runManual(ctx, command, s.handleManualCycle)
+	case "execute_cycles":
+		return s.handleExecuteCycles(cmd)
+	case "job_status":
+		return s.handleJobStatus(cmd)
+	case "job_result":
+		return s.handleJobResult(cmd)
+	case "cancel_job":
+		return s.handleCancelJob(cmd)
 	case "start":
-		return s.handleStart()
+		return s.handleStart(ctx, cmd)
 	case "stop":
-		return s.handleStop()
+		return s.handleStop(ctx)
+	case "abort":
+		return s.handleAbort(ctx, cmd)
 	case "status":
 		return s.handleStatus()
+	case "history":
+		return s.handleHistory(cmd)
+	case "drift_baseline":
+		return s.handleDriftBaseline()
+	case "events":
+		return s.handleEvents(ctx, cmd)
+	case "annotate":
+		return s.handleAnnotate(ctx, cmd)
+	case "selftest":
+		return s.handleSelftest(ctx)
+	case "reset":
+		return s.handleReset()
+	case "grip":
+		return s.runManual(ctx, command, s.handleGrip)
+	case "release":
+		return s.runManual(ctx, command, s.handleRelease)
 	default:
 		return nil, fmt.Errorf("unknown command: %s", command)
 	}
 }
 
+// handleExecuteCycle runs one cycle of the active trial, or a standalone
+// cycle when no trial is active.
 func (s *kettleCycleTestController) handleExecuteCycle(ctx context.Context) (map[string]interface{}, error) {
+	return s.executeCycle(ctx, false)
+}
+
+// handleManualCycle runs an operator-requested cycle. It is recorded in
+// history as manual and is kept out of any active trial: the trial's cycle
+// count, baselines, statistics and fault state are left alone, and its data
+// is not labelled with the trial.
+func (s *kettleCycleTestController) handleManualCycle(ctx context.Context) (map[string]interface{}, error) {
+	return s.executeCycle(ctx, true)
+}
+
+func (s *kettleCycleTestController) executeCycle(ctx context.Context, manual bool) (result map[string]interface{}, err error) {
+	startedAt := time.Now()
+
+	// The cycle runs under its own context so an interlock or the watchdog
+	// can cancel it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Increment cycle count at start so all captured data uses correct cycle number
 	s.mu.Lock()
-	if s.activeTrial != nil {
+	if s.interlock != "" {
+		latched := s.interlock
+		s.mu.Unlock()
+		return nil, fmt.Errorf("interlocked by %s; clear it and send reset", latched)
+	}
+	s.cycleCancel = cancel
+	var trialID string
+	var cycleCount int
+	var metadata trialMetadata
+	if s.activeTrial != nil && !manual {
 		s.activeTrial.cycleCount++
+		trialID = s.activeTrial.trialID
+		cycleCount = s.activeTrial.cycleCount
+		metadata = s.activeTrial.metadata
 	}
+	w := &cycleWatch{trialID: trialID, cycleCount: cycleCount, startedAt: startedAt, cancel: cancel, manual: manual}
+	s.watch = w
 	s.mu.Unlock()
 
+	// A watchdog trip or a panic ends the cycle as a recorded fault; any
+	// other error is recorded with what the cycle had done so far
+	timing := cycleTiming{}
+	var partial map[string]interface{}
+	defer func() {
+		recovered := recover()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if recovered != nil || err != nil {
+			if faulted := s.finishWatchLocked(w, timing, recovered); faulted != nil {
+				result, err = faulted, nil
+			}
+		}
+		if err != nil {
+			s.recordErroredCycleLocked(w, timing, partial, err)
+			s.events.add(event{at: time.Now(), kind: eventPhaseError, trialID: w.trialID, cycleCount: w.cycleCount,
+				message: err.Error(), data: map[string]interface{}{"phase": s.currentPhaseLocked()}})
+		}
+		s.cycleCancel = nil
+		if s.watch == w {
+			s.watch = nil
+		}
+	}()
+
+	result = map[string]interface{}{"status": "completed"}
+	if manual {
+		result["manual"] = true
+	}
+	partial = result
+
+	// Check the kettle is on the load cell before lifting it
+	if s.cfg.RestingWeight != nil && s.forceSensor != nil {
+		s.beginPhase(phaseRestingWeight)
+		reason, err := s.checkRestingWeight(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			s.mu.Lock()
+			s.faultCycleLocked(result, reason, fmt.Sprintf("resting weight %.2f", result["resting_weight"]))
+			s.recordCycleLocked(trialID, cycleCount, startedAt, time.Since(startedAt), timing, result)
+			s.mu.Unlock()
+			return result, nil
+		}
+	}
+
+	// Grip the handle and confirm it is held before lifting
+	var imageTags []string
+	if s.gripper != nil {
+		s.beginPhase(phaseGrip)
+		held, err := s.gripKettle(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+		if !held {
+			// Snapshot the failed grip so vision data can be matched to it
+			if s.camera != nil && s.dataClient != nil {
+				if err := s.captureAndUploadImage(ctx, tagGripFailed); err != nil {
+					s.logger.Warnf("failed to capture grip failure image: %v", err)
+				}
+			}
+			s.releaseKettle(ctx)
+			s.mu.Lock()
+			s.faultCycleLocked(result, faultGripFailed, "gripper not holding the handle before lift")
+			s.recordCycleLocked(trialID, cycleCount, startedAt, time.Since(startedAt), timing, result)
+			s.mu.Unlock()
+			return result, nil
+		}
+		imageTags = append(imageTags, tagGripHeld)
+	}
+
+	s.startTelemetryPhase(ctx, telemetryPhaseLift, trialID, cycleCount, metadata)
+	phaseStart := s.beginPhase(phaseMoveToPourPrep)
 	if err := s.pourPrep.SetPosition(ctx, 2, nil); err != nil {
+		s.endTelemetryPhase(context.Background(), result)
 		return nil, fmt.Errorf("moving to pour_prep position: %w", err)
 	}
+	timing.since(phaseMoveToPourPrep, phaseStart)
 
 	// Wait for arm to reach pour-prep position
-	if err := s.waitForArmStopped(ctx); err != nil {
+	phaseStart = s.beginPhase(phaseSettle)
+	err = s.waitForArmStopped(ctx)
+	timing.since(phaseSettle, phaseStart)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.endTelemetryPhase(context.Background(), result)
+			return nil, fmt.Errorf("waiting at pour_prep position: %w", ctx.Err())
+		}
 		s.logger.Warnf("error waiting for arm to stop at pour-prep: %v", err)
 	}
+	s.endTelemetryPhase(ctx, result)
+
+	// A missed pour-prep pose may mean a collision, so the arm stays put
+	s.beginPhase(phasePoseCheck)
+	onPose, err := s.checkPose(ctx, posePourPrep, result)
+	if err != nil {
+		return nil, err
+	}
+	if !onPose {
+		s.mu.Lock()
+		s.faultCycleLocked(result, faultPoseOutOfTolerance, "arm settled away from the pour_prep pose")
+		s.recordCycleLocked(trialID, cycleCount, startedAt, time.Since(startedAt), timing, result)
+		s.mu.Unlock()
+		return result, nil
+	}
+
+	// Check the kettle left the load cell; if not, put the arm back and fail
+	if s.cfg.LiftCheck != nil && s.forceSensor != nil {
+		s.beginPhase(phaseLiftCheck)
+		lifted, err := s.checkLift(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+		if !lifted {
+			s.mu.Lock()
+			s.faultCycleLocked(result, faultLiftFailed, fmt.Sprintf("residual force %.2f at pour-prep", result["lift_residual"]))
+			s.mu.Unlock()
+			s.startTelemetryPhase(ctx, telemetryPhasePutDown, trialID, cycleCount, metadata)
+			phaseStart = s.beginPhase(phaseReturn)
+			if err := s.resting.SetPosition(ctx, 2, nil); err != nil {
+				s.endTelemetryPhase(context.Background(), result)
+				return nil, fmt.Errorf("returning to resting position: %w", err)
+			}
+			if err := s.waitForArmStopped(ctx); err != nil {
+				s.logger.Warnf("error waiting for arm to stop: %v", err)
+			}
+			timing.since(phaseReturn, phaseStart)
+			s.endTelemetryPhase(context.Background(), result)
+			if s.gripper != nil {
+				s.releaseKettle(ctx)
+			}
+			s.mu.Lock()
+			s.recordCycleLocked(trialID, cycleCount, startedAt, time.Since(startedAt), timing, result)
+			s.mu.Unlock()
+			return result, nil
+		}
+	}
 
 	// Capture and upload image if camera is configured
 	if s.camera != nil && s.dataClient != nil {
-		if err := s.captureAndUploadImage(ctx); err != nil {
+		phaseStart = s.beginPhase(phaseImageCapture)
+		img, err := s.captureImage(ctx)
+		if err != nil {
 			return nil, fmt.Errorf("capturing image: %w", err)
 		}
+		timing.since(phaseImageCapture, phaseStart)
+
+		phaseStart = s.beginPhase(phaseUpload)
+		if err := s.uploadImage(ctx, img, imageTags...); err != nil {
+			return nil, fmt.Errorf("uploading image: %w", err)
+		}
+		timing.since(phaseUpload, phaseStart)
 	}
 
-	// Start force capture if sensor is configured
+	// Start force capture if sensor is configured. The force_capture timing
+	// spans the whole capture window, from start_capture through end_capture.
+	var captureStart time.Time
 	if s.forceSensor != nil {
 		s.mu.Lock()
 		captureCmd := map[string]interface{}{"command": "start_capture"}
-		if s.activeTrial != nil {
-			captureCmd["trial_id"] = s.activeTrial.trialID
-			captureCmd["cycle_count"] = s.activeTrial.cycleCount
+		if trial := s.cycleTrialLocked(); trial != nil {
+			captureCmd["trial_id"] = trial.trialID
+			captureCmd["cycle_count"] = trial.cycleCount
+			trial.metadata.addCaptureFields(captureCmd)
 		}
 		s.mu.Unlock()
 
+		captureStart = s.beginPhase(phaseForceCapture)
 		_, err := s.forceSensor.DoCommand(ctx, captureCmd)
 		if err != nil {
 			s.logger.Warnf("failed to start force capture: %v", err)
 		}
 	}
 
+	s.startTelemetryPhase(ctx, telemetryPhasePutDown, trialID, cycleCount, metadata)
+	phaseStart = s.beginPhase(phaseReturn)
 	if err := s.resting.SetPosition(ctx, 2, nil); err != nil {
 		// Try to end capture on error
 		if s.forceSensor != nil {
 			s.forceSensor.DoCommand(ctx, map[string]interface{}{"command": "end_capture"})
 		}
+		s.endTelemetryPhase(context.Background(), result)
 		return nil, fmt.Errorf("returning to resting position: %w", err)
 	}
 
 	// Wait for arm to stop moving
 	if err := s.waitForArmStopped(ctx); err != nil {
+		if ctx.Err() != nil {
+			if s.forceSensor != nil {
+				s.forceSensor.DoCommand(context.Background(), map[string]interface{}{"command": "end_capture"})
+			}
+			s.endTelemetryPhase(context.Background(), result)
+			return nil, fmt.Errorf("waiting at resting position: %w", ctx.Err())
+		}
 		s.logger.Warnf("error waiting for arm to stop: %v", err)
 	}
+	timing.since(phaseReturn, phaseStart)
+	s.endTelemetryPhase(ctx, result)
+
+	s.beginPhase(phasePoseCheck)
+	onPose, err = s.checkPose(ctx, poseResting, result)
+	if err != nil {
+		s.logger.Warnf("failed to check resting pose: %v", err)
+		onPose = true
+	}
+
+	if s.gripper != nil {
+		s.beginPhase(phaseRelease)
+		s.releaseKettle(ctx)
+	}
 
 	// End force capture
 	var captureResult map[string]interface{}
 	if s.forceSensor != nil {
 		var err error
+		s.beginPhase(phaseForceCapture)
 		captureResult, err = s.forceSensor.DoCommand(ctx, map[string]interface{}{"command": "end_capture"})
+		timing.since(phaseForceCapture, captureStart)
 		if err != nil {
 			s.logger.Warnf("failed to end force capture: %v", err)
 		} else {
-			s.logger.Infof("force capture: %v", captureResult)
+			s.logger.Infof("force capture: %v samples, max force %v, flags %v",
+				captureResult["sample_count"], captureResult["max_force"], captureResult["impact_flags"])
 		}
 	}
 
 	s.mu.Lock()
-	if s.activeTrial != nil {
-		s.activeTrial.lastCycleAt = time.Now()
+	if trial := s.cycleTrialLocked(); trial != nil {
+		trial.lastCycleAt = time.Now()
 	}
 	s.mu.Unlock()
 
+	phaseStart = s.beginPhase(phaseDwell)
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-time.After(1 * time.Second):
 	}
+	timing.since(phaseDwell, phaseStart)
 
-	result := map[string]interface{}{"status": "completed"}
+	duration := time.Since(startedAt)
 	if captureResult != nil {
 		result["force_capture"] = captureResult
+		s.applyImpactRules(captureResult, result)
+		s.scoreDrift(captureResult, result)
+	}
+	s.chartCycle(captureResult, duration, result)
+	if !onPose {
+		s.mu.Lock()
+		s.faultCycleLocked(result, faultPoseOutOfTolerance, "arm settled away from the resting pose")
+		s.mu.Unlock()
 	}
+
+	s.mu.Lock()
+	s.recordCycleLocked(trialID, cycleCount, startedAt, duration, timing, result)
+	s.mu.Unlock()
 	return result, nil
 }
 
+// chartCycle adds the cycle's metrics to the trial's control charts and
+// notifies on any rule violations.
+func (s *kettleCycleTestController) chartCycle(captureResult map[string]interface{}, duration time.Duration, result map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trial := s.cycleTrialLocked()
+	if trial == nil || trial.spc == nil {
+		return
+	}
+
+	values := map[string]float64{spcCycleDuration: duration.Seconds()}
+	if captureResult != nil {
+		if v, ok := toFloat64(captureResult["max_force"]); ok {
+			values[spcMaxForce] = v
+		}
+		if v, ok := toFloat64(captureResult["impulse"]); ok {
+			values[spcImpulse] = v
+		}
+	}
+
+	violations := trial.spc.observe(values)
+	if len(violations) == 0 {
+		return
+	}
+	names := make([]string, len(violations))
+	for i, v := range violations {
+		names[i] = v.String()
+	}
+	result["spc_violations"] = toInterfaceList(names)
+	s.notifyLocked("spc_violation", fmt.Sprintf("trial %s cycle %d: control chart violations %v",
+		trial.trialID, trial.cycleCount, names))
+}
+
+// notifyLocked raises an operator notification. It is logged as an error and
+// kept as last_notification in status, which data capture syncs so a Viam
+// trigger can alert on it. Callers hold s.mu.
+func (s *kettleCycleTestController) notifyLocked(kind, message string) {
+	s.logger.Errorf("%s: %s", kind, message)
+	s.lastNotification = fmt.Sprintf("%s %s: %s", time.Now().Format(time.RFC3339), kind, message)
+	s.recordEventLocked(kind, message, nil)
+}
+
+func (s *kettleCycleTestController) handleHistory(cmd map[string]interface{}) (map[string]interface{}, error) {
+	trialID, _ := cmd["trial_id"].(string)
+	limit := 0
+	if l, ok := toFloat64(cmd["limit"]); ok {
+		limit = int(l)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]interface{}{"cycles": s.history.query(trialID, limit)}, nil
+}
+
+// handleDriftBaseline returns the active trial's drift baseline: the mean
+// profile and its envelope, or nil fields while the baseline is being learned.
+func (s *kettleCycleTestController) handleDriftBaseline() (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.activeTrial == nil {
+		return nil, fmt.Errorf("no active trial")
+	}
+	drift := s.activeTrial.drift
+	if drift == nil {
+		return nil, fmt.Errorf("drift detection is not configured")
+	}
+
+	result := map[string]interface{}{
+		"trial_id":             s.activeTrial.trialID,
+		"drift_baseline_ready": drift.baseline != nil,
+		"points":               driftProfilePoints,
+		"mean":                 nil,
+		"lower":                nil,
+		"upper":                nil,
+	}
+	for k, v := range drift.envelope() {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// scoreDrift compares the put-down profile with the trial's baseline and
+// alerts once enough consecutive cycles have drifted.
+func (s *kettleCycleTestController) scoreDrift(captureResult, result map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trial := s.cycleTrialLocked()
+	if trial == nil || trial.drift == nil {
+		return
+	}
+
+	drift := trial.drift
+	score, outside, scored, newAlert := drift.observe(floatList(captureResult["samples"]))
+	if !scored {
+		return
+	}
+	result["drift_score"] = score
+	result["drift_outside_fraction"] = outside
+	if newAlert {
+		s.notifyLocked("drift_alert", fmt.Sprintf("trial %s: force profile drifted from baseline for %d consecutive cycles (score %.3f, threshold %.3f; %.0f%% of points outside the envelope, limit %.0f%%)",
+			trial.trialID, drift.consecutive, score, drift.cfg.Threshold, outside*100, drift.cfg.MaxOutside*100))
+	}
+}
+
+// applyImpactRules warns about or faults on the put-down's impact flags and
+// records the outcome in the cycle result.
+func (s *kettleCycleTestController) applyImpactRules(captureResult, result map[string]interface{}) {
+	faults, warnings := evaluateImpactRules(s.cfg.ImpactRules, captureResult)
+	if len(warnings) > 0 {
+		s.logger.Warnf("put-down warnings: %v", warnings)
+		result["warnings"] = toInterfaceList(warnings)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if flags := stringList(captureResult["impact_flags"]); len(flags) > 0 {
+		s.recordEventLocked(eventForceAnomaly, fmt.Sprintf("put-down flagged %v", flags),
+			map[string]interface{}{"impact_flags": toInterfaceList(flags), "max_force": captureResult["max_force"]})
+	}
+	if trial := s.cycleTrialLocked(); trial != nil && len(warnings) > 0 {
+		trial.warningCount += len(warnings)
+		trial.lastWarning = warnings[len(warnings)-1]
+	}
+	if len(faults) == 0 {
+		return
+	}
+
+	s.faultCycleLocked(result, faults[0], fmt.Sprintf("put-down flagged %v", faults))
+}
+
+// faultCycleLocked marks the cycle result faulted and, during a trial, faults
+// the trial so cycling stops and notifies. Callers hold s.mu.
+func (s *kettleCycleTestController) faultCycleLocked(result map[string]interface{}, reason, detail string) {
+	result["status"] = "faulted"
+	result["fault"] = reason
+	trial := s.cycleTrialLocked()
+	if trial == nil {
+		s.logger.Errorf("cycle faulted (%s): %s", reason, detail)
+		return
+	}
+	s.notifyLocked("trial_faulted", fmt.Sprintf("trial %s faulted at cycle %d (%s): %s",
+		trial.trialID, trial.cycleCount, reason, detail))
+	trial.fault = reason
+	trial.stop()
+}
+
+// cycleTrialLocked is the trial the in-flight cycle belongs to: the active
+// trial, unless the cycle is a manual one. Callers hold s.mu.
+func (s *kettleCycleTestController) cycleTrialLocked() *trialState {
+	if s.watch != nil && s.watch.manual {
+		return nil
+	}
+	return s.activeTrial
+}
+
 func (s *kettleCycleTestController) waitForArmStopped(ctx context.Context) error {
 	ticker := time.NewTicker(50 * time.Millisecond)
 	defer ticker.Stop()
@@ -319,42 +981,64 @@ func (s *kettleCycleTestController) waitForArmStopped(ctx context.Context) error
 	}
 }
 
-func (s *kettleCycleTestController) captureAndUploadImage(ctx context.Context) error {
+// captureAndUploadImage uploads a camera image tagged with the trial and
+// cycle, plus any extra tags.
+func (s *kettleCycleTestController) captureAndUploadImage(ctx context.Context, extraTags ...string) error {
+	img, err := s.captureImage(ctx)
+	if err != nil {
+		return err
+	}
+	return s.uploadImage(ctx, img, extraTags...)
+}
+
+// captureImage gets and decodes an image from the camera.
+func (s *kettleCycleTestController) captureImage(ctx context.Context) (image.Image, error) {
 	// Get raw image bytes from camera
 	s.logger.Info("capturing image from camera")
 	imageBytes, _, err := s.camera.Image(ctx, "image/jpeg", nil)
 	if err != nil {
-		return fmt.Errorf("getting image from camera: %w", err)
+		return nil, fmt.Errorf("getting image from camera: %w", err)
 	}
 	if len(imageBytes) == 0 {
-		return fmt.Errorf("camera returned empty image")
+		return nil, fmt.Errorf("camera returned empty image")
 	}
 	s.logger.Infof("got %d bytes from camera", len(imageBytes))
 
 	// Decode using image.Decode which auto-detects format
 	img, format, err := image.Decode(bytes.NewReader(imageBytes))
 	if err != nil {
-		return fmt.Errorf("decoding image: %w", err)
+		return nil, fmt.Errorf("decoding image: %w", err)
 	}
 	if img == nil {
-		return fmt.Errorf("decoded image is nil")
+		return nil, fmt.Errorf("decoded image is nil")
 	}
 	s.logger.Infof("decoded image: format=%s, bounds=%v", format, img.Bounds())
+	return img, nil
+}
 
+// uploadImage uploads an image tagged with the trial and cycle, plus any
+// extra tags.
+func (s *kettleCycleTestController) uploadImage(ctx context.Context, img image.Image, extraTags ...string) error {
 	// Build tags from current trial state
 	s.mu.Lock()
 	var trialID string
 	var cycleCount int
-	if s.activeTrial != nil {
-		trialID = s.activeTrial.trialID
-		cycleCount = s.activeTrial.cycleCount
+	if trial := s.cycleTrialLocked(); trial != nil {
+		trialID = trial.trialID
+		cycleCount = trial.cycleCount
 	}
 	s.mu.Unlock()
 
-	tags := formatCaptureTags(trialID, cycleCount)
+	tags := append(formatCaptureTags(trialID, cycleCount), extraTags...)
+	s.mu.Lock()
+	if trial := s.cycleTrialLocked(); trial != nil && trial.trialID == trialID {
+		tags = append(tags, trial.metadata.tags()...)
+		tags = append(tags, annotationTags(trial.annotationsFor(cycleCount))...)
+	}
+	s.mu.Unlock()
 	s.logger.Infof("uploading to dataset %s with tags %v", s.datasetID, tags)
 
-	_, err = s.dataClient.UploadImageToDatasets(
+	id, err := s.dataClient.UploadImageToDatasets(
 		ctx,
 		s.partID,
 		img,
@@ -364,40 +1048,103 @@ func (s *kettleCycleTestController) captureAndUploadImage(ctx context.Context) e
 		&app.FileUploadOptions{},
 	)
 	if err != nil {
+		s.recordEvent(eventUploadFailed, err.Error(), map[string]interface{}{"tags": toInterfaceList(tags)})
 		return fmt.Errorf("uploading image: %w", err)
 	}
 
 	s.logger.Infof("uploaded image with tags: %v", tags)
+	s.mu.Lock()
+	if trial := s.cycleTrialLocked(); trial != nil && trial.trialID == trialID {
+		trial.addUploadLocked(cycleCount, id)
+	}
+	s.mu.Unlock()
 	return nil
 }
 
-func (s *kettleCycleTestController) handleStart() (map[string]interface{}, error) {
+// handleStart begins a trial described by the command's metadata, checked
+// against the trial_metadata schema when one is configured.
+func (s *kettleCycleTestController) handleStart(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	metadata, err := parseTrialMetadata(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("trial metadata: %w", err)
+	}
+	if s.cfg.TrialMetadata != nil {
+		if err := s.cfg.TrialMetadata.check(metadata); err != nil {
+			return nil, err
+		}
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	err = s.canStartLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
 
-	if s.activeTrial != nil {
-		return nil, fmt.Errorf("trial already running: %s", s.activeTrial.trialID)
+	// Check every configured component before cycling starts
+	report := s.runPreflight(ctx)
+	if !report.passed() {
+		return nil, fmt.Errorf("preflight failed: %s", report.failures())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.canStartLocked(); err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 	trialID := fmt.Sprintf("trial-%s", now.Format("20060102-150405"))
 	stopCh := make(chan struct{})
+	loopDone := make(chan struct{})
 
 	s.activeTrial = &trialState{
 		trialID:   trialID,
 		startedAt: now,
 		stopCh:    stopCh,
+		loopDone:  loopDone,
+		metadata:  metadata,
+		timing:    newTimingStats(s.cfg.TimingWindow),
+	}
+	message := fmt.Sprintf("trial %s started", trialID)
+	if desc := metadata.String(); desc != "" {
+		message = fmt.Sprintf("%s: %s", message, desc)
+	}
+	s.recordEventLocked(eventTrialStarted, message, metadata.toMap())
+	if s.cfg.Drift != nil {
+		s.activeTrial.drift = newDriftMonitor(*s.cfg.Drift)
+	}
+	if s.cfg.SPC != nil {
+		s.activeTrial.spc = newSPCMonitor(*s.cfg.SPC)
 	}
 
 	// Start background cycling loop
-	go s.cycleLoop(stopCh)
+	go s.cycleLoop(trialID, stopCh, loopDone)
 
 	return map[string]interface{}{
-		"trial_id": trialID,
+		"trial_id":  trialID,
+		"metadata":  metadata.toMap(),
+		"preflight": report.toMap(),
 	}, nil
 }
 
-func (s *kettleCycleTestController) cycleLoop(stopCh chan struct{}) {
+// canStartLocked refuses a new trial while one is active or an interlock is
+// latched. Callers hold s.mu.
+func (s *kettleCycleTestController) canStartLocked() error {
+	if s.activeTrial != nil {
+		if s.activeTrial.fault != "" {
+			return fmt.Errorf("trial %s faulted (%s); stop it before starting another", s.activeTrial.trialID, s.activeTrial.fault)
+		}
+		return fmt.Errorf("trial already running: %s", s.activeTrial.trialID)
+	}
+	if s.interlock != "" {
+		return fmt.Errorf("interlocked by %s; clear it and send reset", s.interlock)
+	}
+	return nil
+}
+
+func (s *kettleCycleTestController) cycleLoop(trialID string, stopCh, loopDone chan struct{}) {
+	defer close(loopDone)
 	for {
 		select {
 		case <-stopCh:
@@ -405,29 +1152,47 @@ func (s *kettleCycleTestController) cycleLoop(stopCh chan struct{}) {
 		case <-s.cancelCtx.Done():
 			return
 		default:
+			if s.interlocked() {
+				// Latched: hold the trial until reset
+				select {
+				case <-stopCh:
+					return
+				case <-s.cancelCtx.Done():
+					return
+				case <-time.After(interlockWaitInterval):
+				}
+				continue
+			}
+			// Queued manual commands run between cycles
+			if err := s.acquireForTrial(trialID, stopCh); err != nil {
+				continue
+			}
 			s.handleExecuteCycle(s.cancelCtx)
+			s.executor.release()
 		}
 	}
 }
 
-func (s *kettleCycleTestController) handleStop() (map[string]interface{}, error) {
+// handleStop stops a trial gracefully: the in-flight cycle finishes, and the
+// final count is returned once the arm is at rest.
+func (s *kettleCycleTestController) handleStop(ctx context.Context) (map[string]interface{}, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if s.activeTrial == nil {
+	trial := s.activeTrial
+	if trial == nil {
+		s.mu.Unlock()
 		return nil, fmt.Errorf("no active trial to stop")
 	}
-
 	// Signal the loop to stop
-	close(s.activeTrial.stopCh)
+	trial.stop()
+	s.mu.Unlock()
 
-	result := map[string]interface{}{
-		"trial_id":    s.activeTrial.trialID,
-		"cycle_count": s.activeTrial.cycleCount,
+	if err := trial.waitLoopDone(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for the current cycle to finish: %w", err)
 	}
-	s.activeTrial = nil
-
-	return result, nil
+	if err := s.waitForArmStopped(ctx); err != nil {
+		s.logger.Warnf("error waiting for arm to stop: %v", err)
+	}
+	return s.finishTrial(ctx, trial), nil
 }
 
 func (s *kettleCycleTestController) handleStatus() (map[string]interface{}, error) {
@@ -439,13 +1204,46 @@ func (s *kettleCycleTestController) GetState() map[string]interface{} {
 	defer s.mu.Unlock()
 
 	if s.activeTrial == nil {
-		return map[string]interface{}{
+		state := map[string]interface{}{
 			"state":         "idle",
 			"trial_id":      "",
 			"cycle_count":   0,
 			"last_cycle_at": "",
 			"should_sync":   false,
+			"fault":         "",
+			"warning_count": 0,
+			"last_warning":  "",
+
+			"annotation_count": 0,
+			"last_annotation":  "",
+
+			"last_notification": s.lastNotification,
+		}
+		addMetadataState(state, trialMetadata{})
+		if len(s.interlocks) > 0 {
+			state["interlock"] = s.interlock
+			if s.interlock != "" {
+				state["state"] = "interlocked"
+			}
+		}
+		if s.gripper != nil {
+			state["grip_failures"] = 0
+		}
+		if s.cfg.Drift != nil {
+			for k, v := range newDriftMonitor(*s.cfg.Drift).state() {
+				state[k] = v
+			}
+		}
+		if s.cfg.SPC != nil {
+			for k, v := range newSPCMonitor(*s.cfg.SPC).state() {
+				state[k] = v
+			}
 		}
+		for k, v := range newTimingStats(s.cfg.TimingWindow).state() {
+			state[k] = v
+		}
+		s.addExecutorState(state)
+		return state
 	}
 
 	lastCycleAt := ""
@@ -453,13 +1251,61 @@ func (s *kettleCycleTestController) GetState() map[string]interface{} {
 		lastCycleAt = s.activeTrial.lastCycleAt.Format(time.RFC3339)
 	}
 
-	return map[string]interface{}{
-		"state":         "running",
+	state := "running"
+	if s.activeTrial.fault != "" {
+		state = "faulted"
+	}
+	if s.interlock != "" {
+		state = "interlocked"
+	}
+
+	result := map[string]interface{}{
+		"state":         state,
 		"trial_id":      s.activeTrial.trialID,
 		"cycle_count":   s.activeTrial.cycleCount,
 		"last_cycle_at": lastCycleAt,
 		"should_sync":   true,
+		"fault":         s.activeTrial.fault,
+		"warning_count": s.activeTrial.warningCount,
+		"last_warning":  s.activeTrial.lastWarning,
+
+		"annotation_count": len(s.activeTrial.annotations),
+		"last_annotation":  "",
+
+		"last_notification": s.lastNotification,
+	}
+	addMetadataState(result, s.activeTrial.metadata)
+	if n := len(s.activeTrial.annotations); n > 0 {
+		result["last_annotation"] = s.activeTrial.annotations[n-1].String()
+	}
+	if len(s.interlocks) > 0 {
+		result["interlock"] = s.interlock
+		result["interlock_events"] = s.activeTrial.interlockEventList()
+	}
+	if s.gripper != nil {
+		result["grip_failures"] = s.activeTrial.gripFailures
+	}
+	if s.activeTrial.hasRestingWeight {
+		result["last_resting_weight"] = s.activeTrial.lastRestingWeight
+		result["resting_weight_change"] = s.activeTrial.lastRestingWeight - s.activeTrial.firstRestingWeight
+	}
+	if s.activeTrial.drift != nil {
+		for k, v := range s.activeTrial.drift.state() {
+			result[k] = v
+		}
+	}
+	if s.activeTrial.spc != nil {
+		for k, v := range s.activeTrial.spc.state() {
+			result[k] = v
+		}
+	}
+	if s.activeTrial.timing != nil {
+		for k, v := range s.activeTrial.timing.state() {
+			result[k] = v
+		}
 	}
+	s.addExecutorState(result)
+	return result
 }
 
 // formatCaptureTags creates tags for image upload based on trial state.
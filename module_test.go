@@ -24,14 +24,23 @@ func testDeps() (resource.Dependencies, *Config) {
 	testArm.IsMovingFunc = func(ctx context.Context) (bool, error) {
 		return false, nil
 	}
+	testArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return nil
+	}
 	restingSwitch := inject.NewSwitch("resting")
 	restingSwitch.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
 		return nil
 	}
+	restingSwitch.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) {
+		return 2, nil
+	}
 	pourPrepSwitch := inject.NewSwitch("pour-prep")
 	pourPrepSwitch.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
 		return nil
 	}
+	pourPrepSwitch.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) {
+		return 2, nil
+	}
 	deps := resource.Dependencies{
 		resource.NewName(arm.API, "test-arm"):           testArm,
 		resource.NewName(toggleswitch.API, "resting"):   restingSwitch,
@@ -301,7 +310,7 @@ func TestController_ThreadSafety(t *testing.T) {
 	kctrl := newTestController(t)
 
 	// Start active trial
-	kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
 
 	// Spawn goroutines doing concurrent operations
 	var wg sync.WaitGroup
@@ -317,7 +326,7 @@ func TestController_ThreadSafety(t *testing.T) {
 	}
 
 	wg.Wait()
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
 
 // --- Integration: Trial State Machine ---
@@ -325,18 +334,18 @@ func TestController_ThreadSafety(t *testing.T) {
 func TestTrial_StartWhileRunning_Errors(t *testing.T) {
 	kctrl := newTestController(t)
 
-	kctrl.handleStart()
-	_, err := kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
+	_, err := kctrl.handleStart(context.Background(), nil)
 	if err == nil {
 		t.Error("expected error when starting already-running trial")
 	}
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
 
 func TestTrial_StopWhileIdle_Errors(t *testing.T) {
 	kctrl := newTestController(t)
 
-	_, err := kctrl.handleStop()
+	_, err := kctrl.handleStop(context.Background())
 	if err == nil {
 		t.Error("expected error when stopping with no active trial")
 	}
@@ -350,7 +359,7 @@ func TestTrial_Start_InitializesState(t *testing.T) {
 		t.Error("expected nil activeTrial before start")
 	}
 
-	result, err := kctrl.handleStart()
+	result, err := kctrl.handleStart(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("handleStart failed: %v", err)
 	}
@@ -369,16 +378,16 @@ func TestTrial_Start_InitializesState(t *testing.T) {
 		t.Error("returned trial_id doesn't match activeTrial.trialID")
 	}
 
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
 
 func TestTrial_Stop_CleansState(t *testing.T) {
 	kctrl := newTestController(t)
 
-	kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
 	trialID := kctrl.activeTrial.trialID
 
-	result, err := kctrl.handleStop()
+	result, err := kctrl.handleStop(context.Background())
 	if err != nil {
 		t.Fatalf("handleStop failed: %v", err)
 	}
@@ -396,7 +405,7 @@ func TestTrial_CycleCountStartsAtZero(t *testing.T) {
 	kctrl := newTestController(t)
 
 	// Start trial, immediately check status
-	kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
 	state := kctrl.GetState()
 
 	// Verify cycle_count = 0
@@ -407,7 +416,7 @@ func TestTrial_CycleCountStartsAtZero(t *testing.T) {
 		t.Errorf("expected state=running, got %v", state["state"])
 	}
 
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
 
 func TestTrial_StatusReturnsTrialState(t *testing.T) {
@@ -420,11 +429,11 @@ func TestTrial_StatusReturnsTrialState(t *testing.T) {
 	}
 
 	// Running state
-	kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
 	status, _ = kctrl.handleStatus()
 	if status["state"] != "running" {
 		t.Errorf("expected state=running, got %v", status["state"])
 	}
 
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
@@ -0,0 +1,171 @@
+package kettlecycletest
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timed phases of a cycle, in the order they run.
+const (
+	phaseMoveToPourPrep = "move_to_pour_prep" // pour_prep switch command
+	phaseSettle         = "settle"            // waiting for the arm to stop at pour-prep
+	phaseImageCapture   = "image_capture"     // camera image and decode
+	phaseUpload         = "upload"            // image upload to the dataset
+	phaseForceCapture   = "force_capture"     // force sensor capture window, start_capture through end_capture (overlaps return)
+	phaseReturn         = "return"            // move back to resting until the arm stops
+	phaseDwell          = "dwell"             // pause before the next cycle
+)
+
+var cyclePhases = []string{
+	phaseMoveToPourPrep, phaseSettle, phaseImageCapture, phaseUpload,
+	phaseForceCapture, phaseReturn, phaseDwell,
+}
+
+const defaultTimingWindow = 20
+
+// cycleTiming is how long each phase of one cycle took. Phases that did not
+// run are absent.
+type cycleTiming map[string]time.Duration
+
+// since adds the time elapsed from start to the phase.
+func (t cycleTiming) since(phase string, start time.Time) {
+	t[phase] += time.Since(start)
+}
+
+func (t cycleTiming) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(t))
+	for phase, d := range t {
+		m[phase] = durationMs(d)
+	}
+	return m
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// timingStats keeps the phase timings of a trial's most recent completed
+// cycles for rolling averages.
+type timingStats struct {
+	window    int
+	cycles    []cycleTiming
+	durations []time.Duration
+}
+
+func newTimingStats(window int) *timingStats {
+	if window <= 0 {
+		window = defaultTimingWindow
+	}
+	return &timingStats{window: window}
+}
+
+func (ts *timingStats) add(t cycleTiming, duration time.Duration) {
+	if len(ts.cycles) >= ts.window {
+		ts.cycles = append(ts.cycles[:0], ts.cycles[1:]...)
+		ts.durations = append(ts.durations[:0], ts.durations[1:]...)
+	}
+	ts.cycles = append(ts.cycles, t)
+	ts.durations = append(ts.durations, duration)
+}
+
+// phaseAverage is the phase's mean over the cycles in the window it ran in.
+func (ts *timingStats) phaseAverage(phase string) (time.Duration, bool) {
+	var total time.Duration
+	var n int
+	for _, t := range ts.cycles {
+		if d, ok := t[phase]; ok {
+			total += d
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return total / time.Duration(n), true
+}
+
+// cycleAverage is the mean cycle duration over the window.
+func (ts *timingStats) cycleAverage() (time.Duration, bool) {
+	if len(ts.durations) == 0 {
+		return 0, false
+	}
+	var total time.Duration
+	for _, d := range ts.durations {
+		total += d
+	}
+	return total / time.Duration(len(ts.durations)), true
+}
+
+// state reports per-phase averages over the window, the throughput they
+// imply, and the phase taking longest on average.
+func (ts *timingStats) state() map[string]interface{} {
+	averages := map[string]interface{}{}
+	var slowest string
+	var slowestAvg time.Duration
+	for _, phase := range cyclePhases {
+		avg, ok := ts.phaseAverage(phase)
+		if !ok {
+			continue
+		}
+		averages[phase] = durationMs(avg)
+		if avg > slowestAvg {
+			slowest, slowestAvg = phase, avg
+		}
+	}
+
+	var cyclesPerHour float64
+	if avg, ok := ts.cycleAverage(); ok && avg > 0 {
+		cyclesPerHour = float64(time.Hour) / float64(avg)
+	}
+	return map[string]interface{}{
+		"phase_averages_ms": averages,
+		"cycles_per_hour":   cyclesPerHour,
+		"slowest_phase":     slowest,
+	}
+}
+
+// recordCycleLocked adds the phase breakdown and any annotations to the
+// result, feeds a completed cycle into the trial's rolling averages, and
+// appends the history record.
+func (s *kettleCycleTestController) recordCycleLocked(trialID string, cycleCount int, startedAt time.Time, duration time.Duration, timing cycleTiming, result map[string]interface{}) {
+	result["phase_timings_ms"] = timing.toMap()
+	if result["status"] == "completed" && s.activeTrial != nil && s.activeTrial.trialID == trialID && s.activeTrial.timing != nil {
+		s.activeTrial.timing.add(timing, duration)
+	}
+	record := newCycleRecord(trialID, cycleCount, startedAt, duration, result)
+	var specimenID string
+	if s.activeTrial != nil && s.activeTrial.trialID == trialID {
+		record.metadata = s.activeTrial.metadata.toMap()
+		result["metadata"] = record.metadata
+		specimenID = s.activeTrial.metadata.fields[metaSpecimenID]
+		record.annotations = s.activeTrial.annotationsFor(cycleCount)
+		if len(record.annotations) > 0 {
+			result["annotations"] = annotationList(record.annotations)
+		}
+	}
+	s.history.add(record)
+
+	if result["status"] == "error" {
+		return // the phase_error event reports it
+	}
+	kind, message := eventCycleCompleted, fmt.Sprintf("cycle %d completed in %v", cycleCount, duration.Round(time.Millisecond))
+	if result["status"] != "completed" {
+		kind, message = eventCycleFaulted, fmt.Sprintf("cycle %d %s: %v", cycleCount, result["status"], result["fault"])
+	}
+	s.events.add(event{at: time.Now(), kind: kind, trialID: trialID, specimenID: specimenID, cycleCount: cycleCount, message: message,
+		data: map[string]interface{}{"status": result["status"], "fault": result["fault"], "duration_ms": duration.Milliseconds()}})
+}
+
+// recordErroredCycleLocked records a cycle that returned an error in history,
+// with what its result held so far and the timings of the phases that ran,
+// including the one it stopped in.
+func (s *kettleCycleTestController) recordErroredCycleLocked(w *cycleWatch, timing cycleTiming, partial map[string]interface{}, err error) {
+	phase := s.currentPhaseLocked()
+	if _, timed := timing[phase]; !timed && contains(cyclePhases, phase) && s.watch == w {
+		timing.since(phase, w.phaseStartedAt)
+	}
+	partial["status"] = "error"
+	partial["error"] = err.Error()
+	partial["phase"] = phase
+	s.recordCycleLocked(w.trialID, w.cycleCount, w.startedAt, time.Since(w.startedAt), timing, partial)
+}
@@ -0,0 +1,127 @@
+package kettlecycletest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestTimingStats(t *testing.T) {
+	ts := newTimingStats(2)
+	ts.add(cycleTiming{phaseReturn: 4 * time.Second, phaseDwell: time.Second}, 10*time.Second)
+	ts.add(cycleTiming{phaseReturn: 2 * time.Second, phaseDwell: time.Second}, 20*time.Second)
+	ts.add(cycleTiming{phaseReturn: 4 * time.Second, phaseDwell: time.Second, phaseUpload: 5 * time.Second}, 10*time.Second)
+
+	state := ts.state()
+	averages := state["phase_averages_ms"].(map[string]interface{})
+	if averages[phaseReturn] != 3000.0 || averages[phaseUpload] != 5000.0 {
+		t.Errorf("expected averages over the last two cycles, got %v", averages)
+	}
+	if state["slowest_phase"] != phaseUpload {
+		t.Errorf("expected upload slowest, got %v", state["slowest_phase"])
+	}
+	if state["cycles_per_hour"] != 240.0 {
+		t.Errorf("expected 240 cycles/hour from a 15s average, got %v", state["cycles_per_hour"])
+	}
+}
+
+func TestController_PhaseTiming(t *testing.T) {
+	kctrl := newTestController(t)
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{}), timing: newTimingStats(0)}
+	kctrl.mu.Unlock()
+
+	result, err := kctrl.handleExecuteCycle(context.Background())
+	if err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	timings := result["phase_timings_ms"].(map[string]interface{})
+	for _, phase := range []string{phaseMoveToPourPrep, phaseSettle, phaseReturn, phaseDwell} {
+		if _, ok := timings[phase]; !ok {
+			t.Errorf("expected %s in phase timings, got %v", phase, timings)
+		}
+	}
+	if _, ok := timings[phaseUpload]; ok {
+		t.Error("expected no upload phase without a camera")
+	}
+	if timings[phaseDwell].(float64) < 1000 {
+		t.Errorf("expected the 1s dwell, got %v", timings[phaseDwell])
+	}
+
+	state := kctrl.GetState()
+	if state["slowest_phase"] != phaseDwell || state["cycles_per_hour"].(float64) <= 0 {
+		t.Errorf("expected dwell slowest with a positive rate, got %v %v", state["slowest_phase"], state["cycles_per_hour"])
+	}
+	history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+	record := history["cycles"].([]interface{})[0].(map[string]interface{})
+	if _, ok := record["phase_timings_ms"]; !ok {
+		t.Errorf("expected phase timings in history, got %v", record)
+	}
+}
+
+func TestController_ForceCaptureTiming(t *testing.T) {
+	kctrl := newTestController(t)
+	force := inject.NewSensor("force")
+	force.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	}
+	kctrl.forceSensor = force
+	resting := inject.NewSwitch("resting")
+	resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+	kctrl.resting = resting
+
+	result, err := kctrl.handleExecuteCycle(context.Background())
+	if err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	// The capture is open for the whole put-down, so it is at least as long
+	// as the return
+	timings := result["phase_timings_ms"].(map[string]interface{})
+	if timings[phaseForceCapture].(float64) < timings[phaseReturn].(float64) || timings[phaseReturn].(float64) < 50 {
+		t.Errorf("expected force_capture to span the return, got %v", timings)
+	}
+}
+
+func TestController_ErroredCycleRecorded(t *testing.T) {
+	kctrl := newTestController(t)
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{}), timing: newTimingStats(0)}
+	kctrl.mu.Unlock()
+	resting := inject.NewSwitch("resting")
+	resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		return errors.New("switch offline")
+	}
+	kctrl.resting = resting
+
+	if _, err := kctrl.handleExecuteCycle(context.Background()); err == nil {
+		t.Fatal("expected the cycle to fail returning to resting")
+	}
+
+	history := kctrl.history.query("test-trial", 0)
+	if len(history) != 1 {
+		t.Fatalf("expected the errored cycle in history, got %v", history)
+	}
+	record := history[0].(map[string]interface{})
+	if record["status"] != "error" || record["phase"] != phaseReturn || !strings.Contains(record["error"].(string), "switch offline") {
+		t.Errorf("expected an error record naming the return phase, got %v", record)
+	}
+	timings := record["phase_timings_ms"].(map[string]interface{})
+	for _, phase := range []string{phaseMoveToPourPrep, phaseSettle, phaseReturn} {
+		if _, ok := timings[phase]; !ok {
+			t.Errorf("expected %s in the partial timings, got %v", phase, timings)
+		}
+	}
+	if _, ok := timings[phaseDwell]; ok {
+		t.Errorf("expected no dwell timing for a cycle that never got there, got %v", timings)
+	}
+	if state := kctrl.GetState(); state["slowest_phase"] != "" {
+		t.Errorf("expected errored cycles kept out of the rolling averages, got %v", state["slowest_phase"])
+	}
+}
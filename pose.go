@@ -0,0 +1,160 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+)
+
+// Saved positions whose pose can be verified, also the expected_poses keys.
+const (
+	poseResting  = "resting"
+	posePourPrep = "pour_prep"
+)
+
+// faultPoseOutOfTolerance is the fault reason when the arm settles away
+// from a saved position's expected pose.
+const faultPoseOutOfTolerance = "pose_out_of_tolerance"
+
+// PositionMM is an end effector position in millimeters.
+type PositionMM struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// PoseCheckConfig is where the arm should settle after moving to a saved
+// position. Joints, end position, or both may be given.
+type PoseCheckConfig struct {
+	JointsDeg           []float64   `json:"joints_deg,omitempty"`            // expected joint positions in degrees
+	JointToleranceDeg   float64     `json:"joint_tolerance_deg,omitempty"`   // allowed per-joint deviation (default: 2)
+	Position            *PositionMM `json:"position_mm,omitempty"`           // expected end position
+	PositionToleranceMM float64     `json:"position_tolerance_mm,omitempty"` // allowed distance from position_mm (default: 5)
+}
+
+func (cfg *PoseCheckConfig) validate() error {
+	if len(cfg.JointsDeg) == 0 && cfg.Position == nil {
+		return fmt.Errorf("joints_deg or position_mm is required")
+	}
+	if cfg.JointToleranceDeg < 0 {
+		return fmt.Errorf("joint_tolerance_deg must not be negative")
+	}
+	if cfg.PositionToleranceMM < 0 {
+		return fmt.Errorf("position_tolerance_mm must not be negative")
+	}
+	return nil
+}
+
+func (cfg PoseCheckConfig) withDefaults() PoseCheckConfig {
+	if cfg.JointToleranceDeg == 0 {
+		cfg.JointToleranceDeg = 2
+	}
+	if cfg.PositionToleranceMM == 0 {
+		cfg.PositionToleranceMM = 5
+	}
+	return cfg
+}
+
+// validateExpectedPoses checks the expected_poses map.
+func validateExpectedPoses(poses map[string]PoseCheckConfig) error {
+	for name, cfg := range poses {
+		if name != poseResting && name != posePourPrep {
+			return fmt.Errorf("unknown position %q (must be %q or %q)", name, poseResting, posePourPrep)
+		}
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// poseDeviation is how far the arm settled from an expected pose.
+type poseDeviation struct {
+	hasJoints   bool
+	maxJointDeg float64 // largest per-joint deviation
+	hasPosition bool
+	positionMM  float64 // distance from the expected end position
+	exceeded    bool
+}
+
+func (d poseDeviation) toMap() map[string]interface{} {
+	m := map[string]interface{}{"within_tolerance": !d.exceeded}
+	if d.hasJoints {
+		m["max_joint_deviation_deg"] = d.maxJointDeg
+	}
+	if d.hasPosition {
+		m["position_deviation_mm"] = d.positionMM
+	}
+	return m
+}
+
+// measurePose compares where the arm is against the expected pose.
+func measurePose(cfg PoseCheckConfig, jointsRad []float64, position *r3.Vector) (poseDeviation, error) {
+	cfg = cfg.withDefaults()
+	var d poseDeviation
+	if len(cfg.JointsDeg) > 0 {
+		if len(jointsRad) != len(cfg.JointsDeg) {
+			return d, fmt.Errorf("arm has %d joints, joints_deg has %d", len(jointsRad), len(cfg.JointsDeg))
+		}
+		d.hasJoints = true
+		for i, rad := range jointsRad {
+			d.maxJointDeg = math.Max(d.maxJointDeg, math.Abs(rad*180/math.Pi-cfg.JointsDeg[i]))
+		}
+		d.exceeded = d.maxJointDeg > cfg.JointToleranceDeg
+	}
+	if cfg.Position != nil && position != nil {
+		d.hasPosition = true
+		expected := r3.Vector{X: cfg.Position.X, Y: cfg.Position.Y, Z: cfg.Position.Z}
+		d.positionMM = position.Sub(expected).Norm()
+		d.exceeded = d.exceeded || d.positionMM > cfg.PositionToleranceMM
+	}
+	return d, nil
+}
+
+// checkPose measures the settled arm against the named position's expected
+// pose and records the deviation in the result. It returns false when the
+// move missed by more than the tolerance.
+func (s *kettleCycleTestController) checkPose(ctx context.Context, name string, result map[string]interface{}) (bool, error) {
+	cfg, ok := s.cfg.ExpectedPoses[name]
+	if !ok {
+		return true, nil
+	}
+
+	var joints []float64
+	if len(cfg.JointsDeg) > 0 {
+		inputs, err := s.arm.JointPositions(ctx, nil)
+		if err != nil {
+			return false, fmt.Errorf("reading joint positions at %s: %w", name, err)
+		}
+		joints = inputs
+	}
+	var position *r3.Vector
+	if cfg.Position != nil {
+		pose, err := s.arm.EndPosition(ctx, nil)
+		if err != nil {
+			return false, fmt.Errorf("reading end position at %s: %w", name, err)
+		}
+		point := pose.Point()
+		position = &point
+	}
+
+	d, err := measurePose(cfg, joints, position)
+	if err != nil {
+		return false, fmt.Errorf("checking pose at %s: %w", name, err)
+	}
+	deviations, _ := result["pose_deviations"].(map[string]interface{})
+	if deviations == nil {
+		deviations = map[string]interface{}{}
+		result["pose_deviations"] = deviations
+	}
+	deviations[name] = d.toMap()
+
+	s.logger.Infof("pose at %s: max joint deviation %.2f deg, position deviation %.2f mm",
+		name, d.maxJointDeg, d.positionMM)
+	if d.exceeded {
+		s.logger.Warnf("arm missed %s pose beyond tolerance: %v", name, d.toMap())
+	}
+	return !d.exceeded, nil
+}
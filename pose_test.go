@@ -0,0 +1,112 @@
+package kettlecycletest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func degToRad(degs ...float64) []float64 {
+	out := make([]float64, len(degs))
+	for i, d := range degs {
+		out[i] = d * math.Pi / 180
+	}
+	return out
+}
+
+func TestMeasurePose(t *testing.T) {
+	cfg := PoseCheckConfig{JointsDeg: []float64{0, 90, -45}, Position: &PositionMM{X: 300, Y: 0, Z: 200}}
+
+	d, err := measurePose(cfg, degToRad(0.5, 89, -45), &r3.Vector{X: 300, Y: 3, Z: 204})
+	if err != nil {
+		t.Fatalf("measurePose failed: %v", err)
+	}
+	if math.Abs(d.maxJointDeg-1) > 1e-9 || d.positionMM != 5 || d.exceeded {
+		t.Errorf("expected 1 deg and 5 mm within tolerance, got %+v", d)
+	}
+
+	d, _ = measurePose(cfg, degToRad(0, 95, -45), &r3.Vector{X: 300, Z: 200})
+	if !d.exceeded {
+		t.Error("expected a 5 degree miss to exceed the 2 degree default")
+	}
+
+	if _, err := measurePose(cfg, degToRad(0, 90), nil); err == nil {
+		t.Error("expected error for a joint count mismatch")
+	}
+
+	if err := validateExpectedPoses(map[string]PoseCheckConfig{"pouring": cfg}); err == nil {
+		t.Error("expected error for unknown position")
+	}
+	if err := validateExpectedPoses(map[string]PoseCheckConfig{poseResting: {}}); err == nil {
+		t.Error("expected error for a pose without joints or position")
+	}
+}
+
+func TestController_PoseCheck(t *testing.T) {
+	newPoseController := func(t *testing.T, joints []float64) (*kettleCycleTestController, *int) {
+		kctrl := newTestController(t)
+		testArm := inject.NewArm("test-arm")
+		testArm.IsMovingFunc = func(ctx context.Context) (bool, error) { return false, nil }
+		testArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]float64, error) {
+			return joints, nil
+		}
+		testArm.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+			return spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 200}), nil
+		}
+		kctrl.arm = testArm
+		returns := 0
+		resting := inject.NewSwitch("resting")
+		resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			returns++
+			return nil
+		}
+		kctrl.resting = resting
+		kctrl.cfg.ExpectedPoses = map[string]PoseCheckConfig{
+			posePourPrep: {JointsDeg: []float64{0, 90, -45}},
+			poseResting:  {Position: &PositionMM{X: 300, Z: 200}},
+		}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+		return kctrl, &returns
+	}
+
+	t.Run("missed pour_prep pose fails the cycle in place", func(t *testing.T) {
+		kctrl, returns := newPoseController(t, degToRad(0, 80, -45))
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "faulted" || result["fault"] != faultPoseOutOfTolerance {
+			t.Errorf("expected pose fault, got %v", result)
+		}
+		if *returns != 0 {
+			t.Error("expected the arm to stay at the missed pose")
+		}
+		deviation := result["pose_deviations"].(map[string]interface{})[posePourPrep].(map[string]interface{})
+		if deviation["within_tolerance"] != false {
+			t.Errorf("expected deviation out of tolerance, got %v", deviation)
+		}
+	})
+
+	t.Run("deviations within tolerance are recorded", func(t *testing.T) {
+		kctrl, _ := newPoseController(t, degToRad(0, 90.5, -45))
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "completed" {
+			t.Errorf("expected completed cycle, got %v", result)
+		}
+		history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+		record := history["cycles"].([]interface{})[0].(map[string]interface{})
+		deviations := record["pose_deviations"].(map[string]interface{})
+		if _, ok := deviations[poseResting]; !ok {
+			t.Errorf("expected both poses in history, got %v", deviations)
+		}
+	})
+}
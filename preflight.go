@@ -0,0 +1,248 @@
+package kettlecycletest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+)
+
+// preflightCheckTimeout bounds each preflight check.
+const preflightCheckTimeout = 5 * time.Second
+
+// Preflight check outcomes.
+const (
+	preflightPass = "pass"
+	preflightFail = "fail"
+	preflightWarn = "warn" // failed, but listed in preflight_warn_only
+)
+
+// Preflight check names, usable in preflight_warn_only.
+const (
+	checkArm           = "arm"
+	checkRestingSwitch = "resting_position"
+	checkPourPrep      = "pour_prep_position"
+	checkGripper       = "gripper"
+	checkCamera        = "camera"
+	checkForceSensor   = "force_sensor"
+	checkDataUpload    = "data_upload"
+	checkVision        = "vision"
+	checkInterlocks    = "interlocks"
+)
+
+var preflightChecks = []string{
+	checkArm, checkRestingSwitch, checkPourPrep, checkGripper, checkCamera,
+	checkForceSensor, checkDataUpload, checkVision, checkInterlocks,
+}
+
+// validatePreflightWarnOnly checks the names in preflight_warn_only.
+func validatePreflightWarnOnly(names []string) error {
+	for _, name := range names {
+		if !hasCheck(name) {
+			return fmt.Errorf("unknown preflight check %q (must be one of %v)", name, preflightChecks)
+		}
+	}
+	return nil
+}
+
+func hasCheck(name string) bool {
+	for _, c := range preflightChecks {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// preflightCheck runs one check, returning a detail on success.
+type preflightCheck struct {
+	name string
+	run  func(context.Context) (string, error)
+}
+
+// preflightResult is the outcome of one check.
+type preflightResult struct {
+	name   string
+	status string
+	detail string
+}
+
+// preflightReport holds the checks run for the configured components.
+type preflightReport struct {
+	results []preflightResult
+}
+
+// passed is false when any required check failed.
+func (r preflightReport) passed() bool {
+	for _, res := range r.results {
+		if res.status == preflightFail {
+			return false
+		}
+	}
+	return true
+}
+
+func (r preflightReport) toMap() map[string]interface{} {
+	checks := make(map[string]interface{}, len(r.results))
+	for _, res := range r.results {
+		checks[res.name] = map[string]interface{}{"status": res.status, "detail": res.detail}
+	}
+	return map[string]interface{}{"passed": r.passed(), "checks": checks}
+}
+
+// failures summarizes the failed required checks for an error message.
+func (r preflightReport) failures() string {
+	var parts []string
+	for _, res := range r.results {
+		if res.status == preflightFail {
+			parts = append(parts, fmt.Sprintf("%s: %s", res.name, res.detail))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// runPreflight checks every configured component. Checks for components
+// that are not configured are left out of the report.
+func (s *kettleCycleTestController) runPreflight(ctx context.Context) preflightReport {
+	checks := []preflightCheck{
+		{checkArm, s.checkArm},
+		{checkRestingSwitch, func(ctx context.Context) (string, error) { return checkSwitch(ctx, s.resting.GetPosition) }},
+		{checkPourPrep, func(ctx context.Context) (string, error) { return checkSwitch(ctx, s.pourPrep.GetPosition) }},
+	}
+	if s.gripper != nil {
+		checks = append(checks, preflightCheck{checkGripper, s.checkGripper})
+	}
+	if s.camera != nil {
+		checks = append(checks, preflightCheck{checkCamera, s.checkCamera})
+	}
+	if s.forceSensor != nil {
+		checks = append(checks, preflightCheck{checkForceSensor, s.checkForceSensor})
+	}
+	if s.dataClient != nil {
+		checks = append(checks, preflightCheck{checkDataUpload, s.checkDataUpload})
+	}
+	if s.vision != nil {
+		checks = append(checks, preflightCheck{checkVision, s.checkVision})
+	}
+	if len(s.interlocks) > 0 {
+		checks = append(checks, preflightCheck{checkInterlocks, s.checkInterlocks})
+	}
+
+	warnOnly := map[string]bool{}
+	for _, name := range s.cfg.PreflightWarnOnly {
+		warnOnly[name] = true
+	}
+
+	var report preflightReport
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, preflightCheckTimeout)
+		detail, err := c.run(checkCtx)
+		cancel()
+		res := preflightResult{name: c.name, status: preflightPass, detail: detail}
+		if err != nil {
+			res.status = preflightFail
+			if warnOnly[c.name] {
+				res.status = preflightWarn
+			}
+			res.detail = err.Error()
+			s.logger.Warnf("preflight %s failed: %v", c.name, err)
+		}
+		report.results = append(report.results, res)
+	}
+	return report
+}
+
+func (s *kettleCycleTestController) checkArm(ctx context.Context) (string, error) {
+	moving, err := s.arm.IsMoving(ctx)
+	if err != nil {
+		return "", fmt.Errorf("arm not responding: %w", err)
+	}
+	if moving {
+		return "", fmt.Errorf("arm is moving")
+	}
+	return "responding, not moving", nil
+}
+
+func checkSwitch(ctx context.Context, getPosition func(context.Context, map[string]interface{}) (uint32, error)) (string, error) {
+	position, err := getPosition(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("switch not responding: %w", err)
+	}
+	return fmt.Sprintf("responding, position %d", position), nil
+}
+
+func (s *kettleCycleTestController) checkGripper(ctx context.Context) (string, error) {
+	status, err := s.gripper.IsHoldingSomething(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("gripper not responding: %w", err)
+	}
+	return fmt.Sprintf("responding, holding %v", status.IsHoldingSomething), nil
+}
+
+func (s *kettleCycleTestController) checkCamera(ctx context.Context) (string, error) {
+	imageBytes, _, err := s.camera.Image(ctx, "image/jpeg", nil)
+	if err != nil {
+		return "", fmt.Errorf("getting image: %w", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(imageBytes))
+	if err != nil {
+		return "", fmt.Errorf("decoding image: %w", err)
+	}
+	return fmt.Sprintf("%s image %v", format, img.Bounds().Size()), nil
+}
+
+func (s *kettleCycleTestController) checkForceSensor(ctx context.Context) (string, error) {
+	readings, err := s.forceSensor.Readings(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("reading force sensor: %w", err)
+	}
+	if state, _ := readings["capture_state"].(string); state != "" && state != "idle" {
+		return "", fmt.Errorf("force sensor is not idle (%s)", state)
+	}
+	if fault, _ := readings["fault"].(string); fault != "" {
+		return "", fmt.Errorf("force sensor fault: %s", fault)
+	}
+	force, err := s.readForce(ctx, 1)
+	if err != nil {
+		return "", fmt.Errorf("reading force: %w", err)
+	}
+	return fmt.Sprintf("idle, reading %.2f", force), nil
+}
+
+func (s *kettleCycleTestController) checkDataUpload(ctx context.Context) (string, error) {
+	datasets, err := s.dataClient.ListDatasetsByIDs(ctx, []string{s.datasetID})
+	if err != nil {
+		return "", fmt.Errorf("data API rejected credentials: %w", err)
+	}
+	if len(datasets) == 0 {
+		return "", fmt.Errorf("dataset %s not found", s.datasetID)
+	}
+	return fmt.Sprintf("dataset %s reachable", s.datasetID), nil
+}
+
+func (s *kettleCycleTestController) checkVision(ctx context.Context) (string, error) {
+	if _, err := s.vision.GetProperties(ctx, nil); err != nil {
+		return "", fmt.Errorf("vision service not responding: %w", err)
+	}
+	return "responding", nil
+}
+
+func (s *kettleCycleTestController) checkInterlocks(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.interlock != "" {
+		return "", fmt.Errorf("interlocked by %s; clear it and send reset", s.interlock)
+	}
+	for _, in := range s.interlocks {
+		if s.interlockTripped[in.cfg.Name] {
+			return "", fmt.Errorf("interlock %s is tripped", in.cfg.Name)
+		}
+	}
+	return "all clear", nil
+}
+
+func (s *kettleCycleTestController) handleSelftest(ctx context.Context) (map[string]interface{}, error) {
+	return s.runPreflight(ctx).toMap(), nil
+}
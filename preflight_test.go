@@ -0,0 +1,90 @@
+package kettlecycletest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func checkStatus(report map[string]interface{}, name string) interface{} {
+	check, ok := report["checks"].(map[string]interface{})[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return check["status"]
+}
+
+func TestPreflight(t *testing.T) {
+	t.Run("config rejects unknown checks", func(t *testing.T) {
+		cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", PreflightWarnOnly: []string{"coffee"}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for unknown preflight check")
+		}
+	})
+
+	t.Run("selftest reports configured components", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.vision = inject.NewVisionService("vision")
+		kctrl.vision.(*inject.VisionService).GetPropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*vision.Properties, error) {
+			return nil, errors.New("model not loaded")
+		}
+
+		report, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "selftest"})
+		if err != nil {
+			t.Fatalf("selftest failed: %v", err)
+		}
+		for _, name := range []string{checkArm, checkRestingSwitch, checkPourPrep} {
+			if checkStatus(report, name) != preflightPass {
+				t.Errorf("expected %s to pass, got %v", name, report)
+			}
+		}
+		if checkStatus(report, checkVision) != preflightFail || report["passed"] != false {
+			t.Errorf("expected vision failure, got %v", report)
+		}
+		if checkStatus(report, checkCamera) != nil {
+			t.Error("expected no camera check without a camera")
+		}
+
+		// A preflight probe of the vision service is not a vision result
+		events, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "events"})
+		for _, e := range events["events"].([]interface{}) {
+			if e.(map[string]interface{})["kind"] == eventVisionResult {
+				t.Errorf("expected no vision_result event from selftest, got %v", e)
+			}
+		}
+	})
+
+	t.Run("start refuses a moving arm", func(t *testing.T) {
+		kctrl := newTestController(t)
+		testArm := inject.NewArm("test-arm")
+		testArm.IsMovingFunc = func(ctx context.Context) (bool, error) { return true, nil }
+		kctrl.arm = testArm
+
+		_, err := kctrl.handleStart(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "arm: arm is moving") {
+			t.Errorf("expected preflight failure for the arm, got %v", err)
+		}
+		if kctrl.GetState()["state"] != "idle" {
+			t.Error("expected no trial after a failed preflight")
+		}
+	})
+
+	t.Run("warn-only checks do not block start", func(t *testing.T) {
+		kctrl := newTestController(t)
+		force := inject.NewSensor("force")
+		force.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"capture_state": "capturing"}, nil
+		}
+		kctrl.forceSensor = force
+		kctrl.cfg.PreflightWarnOnly = []string{checkForceSensor}
+
+		report := kctrl.runPreflight(context.Background())
+		if !report.passed() || checkStatus(report.toMap(), checkForceSensor) != preflightWarn {
+			t.Errorf("expected a force sensor warning, got %v", report.toMap())
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package kettlecycletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	combineSum        = "sum"
+	combineDifference = "difference"
+)
+
+// readingPath locates a numeric value inside a sensor readings map.
+// Paths are either dotted ("channels.0") or JSON pointers ("/channels/0").
+// A dotted path that is itself a top-level key ("ch0.raw") matches that key.
+type readingPath struct {
+	raw      string
+	segments []string
+	pointer  bool
+}
+
+func parseReadingPath(raw string) (readingPath, error) {
+	if raw == "" {
+		return readingPath{}, fmt.Errorf("path is empty")
+	}
+
+	var segments []string
+	if strings.HasPrefix(raw, "/") {
+		// JSON pointer (RFC 6901): ~1 is "/" and ~0 is "~"
+		for _, seg := range strings.Split(raw[1:], "/") {
+			unescaped, err := unescapePointerSegment(seg)
+			if err != nil {
+				return readingPath{}, fmt.Errorf("path %q: %w", raw, err)
+			}
+			segments = append(segments, unescaped)
+		}
+		for i, seg := range segments {
+			if seg == "" {
+				return readingPath{}, fmt.Errorf("path %q: segment %d is empty", raw, i)
+			}
+		}
+		return readingPath{raw: raw, segments: segments, pointer: true}, nil
+	}
+
+	segments = strings.Split(raw, ".")
+	for i, seg := range segments {
+		if seg == "" {
+			return readingPath{}, fmt.Errorf("path %q: segment %d is empty", raw, i)
+		}
+	}
+	return readingPath{raw: raw, segments: segments}, nil
+}
+
+// parseForcePaths parses force_key/force_keys into paths, defaulting to "value".
+func parseForcePaths(forceKey string, forceKeys []string, combine string) ([]readingPath, error) {
+	if forceKey != "" && len(forceKeys) > 0 {
+		return nil, fmt.Errorf("force_key and force_keys are mutually exclusive")
+	}
+	switch combine {
+	case "", combineSum, combineDifference:
+	default:
+		return nil, fmt.Errorf("force_combine %q is invalid (must be %q or %q)", combine, combineSum, combineDifference)
+	}
+	if combine != "" && len(forceKeys) < 2 {
+		return nil, fmt.Errorf("force_combine requires at least two force_keys")
+	}
+
+	if len(forceKeys) == 0 {
+		key := forceKey
+		if key == "" {
+			key = "value"
+		}
+		p, err := parseReadingPath(key)
+		if err != nil {
+			return nil, fmt.Errorf("force_key: %w", err)
+		}
+		return []readingPath{p}, nil
+	}
+
+	paths := make([]readingPath, 0, len(forceKeys))
+	for i, key := range forceKeys {
+		p, err := parseReadingPath(key)
+		if err != nil {
+			return nil, fmt.Errorf("force_keys[%d]: %w", i, err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+func unescapePointerSegment(seg string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(seg); i++ {
+		if seg[i] != '~' {
+			b.WriteByte(seg[i])
+			continue
+		}
+		if i+1 >= len(seg) || (seg[i+1] != '0' && seg[i+1] != '1') {
+			return "", fmt.Errorf("invalid escape in segment %q (use ~0 or ~1)", seg)
+		}
+		if seg[i+1] == '0' {
+			b.WriteByte('~')
+		} else {
+			b.WriteByte('/')
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+func (p readingPath) String() string {
+	return p.raw
+}
+
+// lookup walks the readings along the path and returns the leaf. A dotted
+// path that names a top-level key is that key, so existing keys such as
+// "force.N" keep working.
+func (p readingPath) lookup(readings map[string]interface{}) (interface{}, error) {
+	if !p.pointer {
+		if v, ok := readings[p.raw]; ok {
+			return v, nil
+		}
+	}
+	var cur interface{} = readings
+	for i, seg := range p.segments {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			next, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("path %q: segment %d (%q) not found", p.raw, i, seg)
+			}
+			cur = next
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("path %q: segment %d (%q) is not an index into a list of %d", p.raw, i, seg, len(node))
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("path %q: segment %d (%q) cannot descend into %T", p.raw, i, seg, cur)
+		}
+	}
+	return cur, nil
+}
+
+// extract walks the readings along the path and converts the leaf to float64.
+func (p readingPath) extract(readings map[string]interface{}) (float64, error) {
+	cur, err := p.lookup(readings)
+	if err != nil {
+		return 0, err
+	}
+
+	v, ok := toFloat64(cur)
+	if !ok {
+		return 0, fmt.Errorf("path %q: value is not numeric: %T (%v)", p.raw, cur, cur)
+	}
+	return v, nil
+}
+
+// toFloat64 converts any numeric reading, including numeric strings, to float64.
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// combineReadings reduces several extracted values into one force reading.
+// "difference" subtracts every later value from the first (differential bridge).
+func combineReadings(values []float64, mode string) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := values[0]
+	for _, v := range values[1:] {
+		if mode == combineDifference {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	return total
+}
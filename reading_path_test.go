@@ -0,0 +1,204 @@
+package kettlecycletest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestParseReadingPath(t *testing.T) {
+	t.Run("dotted path", func(t *testing.T) {
+		p, err := parseReadingPath("channels.0")
+		if err != nil {
+			t.Fatalf("parseReadingPath failed: %v", err)
+		}
+		if len(p.segments) != 2 || p.segments[0] != "channels" || p.segments[1] != "0" {
+			t.Errorf("unexpected segments: %v", p.segments)
+		}
+	})
+
+	t.Run("JSON pointer with escapes", func(t *testing.T) {
+		p, err := parseReadingPath("/a~1b/c~0d")
+		if err != nil {
+			t.Fatalf("parseReadingPath failed: %v", err)
+		}
+		if len(p.segments) != 2 || p.segments[0] != "a/b" || p.segments[1] != "c~d" {
+			t.Errorf("unexpected segments: %v", p.segments)
+		}
+	})
+
+	t.Run("rejects empty segments", func(t *testing.T) {
+		for _, raw := range []string{"", "channels..0", "/", "/channels//0"} {
+			if _, err := parseReadingPath(raw); err == nil {
+				t.Errorf("expected error for %q", raw)
+			}
+		}
+	})
+
+	t.Run("rejects bad pointer escape", func(t *testing.T) {
+		if _, err := parseReadingPath("/a~2"); err == nil {
+			t.Error("expected error for invalid ~ escape")
+		}
+	})
+}
+
+func TestReadingPath_Extract(t *testing.T) {
+	readings := map[string]interface{}{
+		"value":  float32(12.5),
+		"counts": int32(-40),
+		"text":   " 7.25 ",
+		"number": json.Number("3"),
+		"label":  "not-a-number",
+		"channels": map[string]interface{}{
+			"0": uint16(100),
+			"1": map[string]interface{}{"raw": int64(30)},
+		},
+		"list": []interface{}{1.5, 2.5},
+	}
+
+	cases := map[string]float64{
+		"value":           12.5,
+		"counts":          -40,
+		"text":            7.25,
+		"number":          3,
+		"channels.0":      100,
+		"/channels/1/raw": 30,
+		"list.1":          2.5,
+		"/list/0":         1.5,
+	}
+	for raw, want := range cases {
+		p, err := parseReadingPath(raw)
+		if err != nil {
+			t.Fatalf("parseReadingPath(%q) failed: %v", raw, err)
+		}
+		got, err := p.extract(readings)
+		if err != nil {
+			t.Errorf("extract(%q) failed: %v", raw, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("extract(%q) = %v, want %v", raw, got, want)
+		}
+	}
+
+	errCases := map[string]string{
+		"missing":        `segment 0 ("missing") not found`,
+		"channels.2":     `segment 1 ("2") not found`,
+		"/channels/1/ch": `segment 2 ("ch") not found`,
+		"list.5":         `segment 1 ("5") is not an index into a list of 2`,
+		"value.x":        `segment 1 ("x") cannot descend into float32`,
+		"label":          "not numeric",
+		"channels.1":     "not numeric",
+	}
+	for raw, want := range errCases {
+		p, _ := parseReadingPath(raw)
+		_, err := p.extract(readings)
+		if err == nil {
+			t.Errorf("extract(%q): expected error", raw)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), raw) {
+			t.Errorf("extract(%q) error = %q, want it to mention %q and the path", raw, err, want)
+		}
+	}
+}
+
+func TestReadingPath_DottedTopLevelKey(t *testing.T) {
+	// Keys that contain dots predate nested paths and must keep matching
+	readings := map[string]interface{}{
+		"force.N": 42.0,
+		"ch0.raw": int32(7),
+		"ch0":     map[string]interface{}{"raw": 99.0},
+	}
+	for raw, want := range map[string]float64{"force.N": 42, "ch0.raw": 7} {
+		p, err := parseReadingPath(raw)
+		if err != nil {
+			t.Fatalf("parseReadingPath(%q) failed: %v", raw, err)
+		}
+		got, err := p.extract(readings)
+		if err != nil || got != want {
+			t.Errorf("extract(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+
+	// A JSON pointer always walks
+	p, _ := parseReadingPath("/ch0/raw")
+	if got, err := p.extract(readings); err != nil || got != 99 {
+		t.Errorf("extract(/ch0/raw) = %v, %v; want 99", got, err)
+	}
+}
+
+func TestForceSensorConfig_ReadingPaths(t *testing.T) {
+	t.Run("defaults to value", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc"}
+		paths, err := cfg.readingPaths()
+		if err != nil {
+			t.Fatalf("readingPaths failed: %v", err)
+		}
+		if len(paths) != 1 || paths[0].raw != "value" {
+			t.Errorf("expected [value], got %v", paths)
+		}
+	})
+
+	t.Run("validation names the failing path", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc", ForceKeys: []string{"a", "b..c"}}
+		_, _, err := cfg.Validate("components.0")
+		if err == nil {
+			t.Fatal("expected validation error")
+		}
+		if !strings.Contains(err.Error(), "force_keys[1]") || !strings.Contains(err.Error(), `"b..c"`) {
+			t.Errorf("error should identify force_keys[1] \"b..c\", got: %v", err)
+		}
+	})
+
+	t.Run("force_key and force_keys are exclusive", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc", ForceKey: "value", ForceKeys: []string{"a", "b"}}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error when both force_key and force_keys set")
+		}
+	})
+
+	t.Run("force_combine must be known and needs two keys", func(t *testing.T) {
+		cfg := &ForceSensorConfig{LoadCell: "adc", ForceKeys: []string{"a", "b"}, ForceCombine: "product"}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for unknown force_combine")
+		}
+		cfg = &ForceSensorConfig{LoadCell: "adc", ForceKey: "a", ForceCombine: combineSum}
+		if _, _, err := cfg.Validate("test"); err == nil {
+			t.Error("expected error for force_combine with a single key")
+		}
+	})
+}
+
+func TestSensorForceReader_Combine(t *testing.T) {
+	loadCell := inject.NewSensor("adc")
+	loadCell.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"channels": map[string]interface{}{"0": int32(120), "1": "20"},
+		}, nil
+	}
+	paths := []readingPath{}
+	for _, raw := range []string{"channels.0", "/channels/1"} {
+		p, _ := parseReadingPath(raw)
+		paths = append(paths, p)
+	}
+
+	sum, err := newSensorForceReader(loadCell, paths, combineSum).ReadForce(context.Background())
+	if err != nil {
+		t.Fatalf("ReadForce failed: %v", err)
+	}
+	if sum != 140 {
+		t.Errorf("sum = %v, want 140", sum)
+	}
+
+	diff, err := newSensorForceReader(loadCell, paths, combineDifference).ReadForce(context.Background())
+	if err != nil {
+		t.Fatalf("ReadForce failed: %v", err)
+	}
+	if diff != 100 {
+		t.Errorf("difference = %v, want 100", diff)
+	}
+}
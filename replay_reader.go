@@ -0,0 +1,310 @@
+package kettlecycletest
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recordedProfile is one put-down force profile loaded from a capture file.
+type recordedProfile struct {
+	cycleCount int // cycle the profile was recorded in, 0 if unknown
+	samples    []float64
+}
+
+// replayForceReader plays back recorded profiles, one per capture window.
+// Each ReadForce call returns the next sample, so playback runs at the
+// force sensor's sample rate. Once a profile ends the last sample is held.
+type replayForceReader struct {
+	profiles []recordedProfile
+	loop     bool
+	byCycle  bool
+
+	mu       sync.Mutex
+	next     int              // next profile index for sequential playback
+	current  *recordedProfile // nil while the kettle is lifted
+	position int
+	err      error // set when no profile is available for this capture
+}
+
+func newReplayForceReader(profiles []recordedProfile, loop, byCycle bool) *replayForceReader {
+	return &replayForceReader{profiles: profiles, loop: loop, byCycle: byCycle}
+}
+
+func (r *replayForceReader) ReadForce(ctx context.Context) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.current == nil || len(r.current.samples) == 0 {
+		return 0, nil
+	}
+	if r.position >= len(r.current.samples) {
+		return r.current.samples[len(r.current.samples)-1], nil
+	}
+	v := r.current.samples[r.position]
+	r.position++
+	return v, nil
+}
+
+func (r *replayForceReader) captureStarted(cycleCount int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.position = 0
+	r.current = nil
+	r.err = nil
+
+	idx, err := r.selectProfile(cycleCount)
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.current = &r.profiles[idx]
+}
+
+func (r *replayForceReader) captureEnded() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = nil
+	r.err = nil
+}
+
+// selectProfile picks the profile for a capture. By cycle, a profile recorded
+// for that cycle wins; otherwise cycle N plays the Nth profile.
+func (r *replayForceReader) selectProfile(cycleCount int) (int, error) {
+	n := len(r.profiles)
+	if r.byCycle && cycleCount > 0 {
+		for i, p := range r.profiles {
+			if p.cycleCount == cycleCount {
+				return i, nil
+			}
+		}
+		idx := cycleCount - 1
+		if idx >= n {
+			if !r.loop {
+				return 0, fmt.Errorf("replay: no profile for cycle %d (%d profiles)", cycleCount, n)
+			}
+			idx %= n
+		}
+		return idx, nil
+	}
+
+	if r.next >= n {
+		if !r.loop {
+			return 0, fmt.Errorf("replay: all %d profiles played", n)
+		}
+		r.next = 0
+	}
+	idx := r.next
+	r.next++
+	return idx, nil
+}
+
+// loadReplayProfiles reads recorded profiles from a .csv or .jsonl file.
+func loadReplayProfiles(path string) ([]recordedProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening replay file: %w", err)
+	}
+	defer f.Close()
+
+	var profiles []recordedProfile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		profiles, err = parseCSVProfiles(f)
+	case ".jsonl", ".ndjson":
+		profiles, err = parseJSONLProfiles(f)
+	default:
+		return nil, fmt.Errorf("replay file %q: unsupported extension (use .csv or .jsonl)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay file %q: %w", path, err)
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("replay file %q contains no profiles", path)
+	}
+	return profiles, nil
+}
+
+// parseJSONLProfiles reads one capture per line, as exported force-sensor
+// readings. The samples array may sit at the top level, under "readings",
+// or under "data.readings" (Viam data export).
+func parseJSONLProfiles(r io.Reader) ([]recordedProfile, error) {
+	prefixes := []string{"", "readings.", "data.readings."}
+
+	var profiles []recordedProfile
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var samples []interface{}
+		var cycle float64
+		for _, prefix := range prefixes {
+			if s, ok := lookupPath(record, prefix+"samples").([]interface{}); ok {
+				samples = s
+				if c, ok := toFloat64(lookupPath(record, prefix+"cycle_count")); ok {
+					cycle = c
+				}
+				break
+			}
+		}
+		if samples == nil {
+			return nil, fmt.Errorf("line %d: no samples array", line)
+		}
+
+		profile := recordedProfile{cycleCount: int(cycle), samples: make([]float64, len(samples))}
+		for i, v := range samples {
+			f, ok := toFloat64(v)
+			if !ok {
+				return nil, fmt.Errorf("line %d: sample %d is not numeric: %v", line, i, v)
+			}
+			profile.samples[i] = f
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// lookupPath returns the value at a dotted path, or nil if it is missing.
+func lookupPath(record map[string]interface{}, path string) interface{} {
+	var cur interface{} = record
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[seg]
+	}
+	return cur
+}
+
+// parseCSVProfiles accepts two layouts:
+//   - long: a header with a "force" column, one sample per row, grouped into
+//     profiles by a "cycle_count" or "profile" column when present
+//   - wide: one profile per row; an optional header whose first column is
+//     "cycle_count" marks that column as the cycle number
+func parseCSVProfiles(r io.Reader) ([]recordedProfile, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	isHeader := false
+	for _, cell := range header {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(cell), 64); err != nil && cell != "" {
+			isHeader = true
+			break
+		}
+	}
+	if !isHeader {
+		return parseWideCSV(rows, false)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	forceCol, ok := columns["force"]
+	if !ok {
+		_, hasCycle := columns["cycle_count"]
+		if !hasCycle || columns["cycle_count"] != 0 {
+			return nil, fmt.Errorf("header must have a \"force\" column (long layout) or start with \"cycle_count\" (wide layout)")
+		}
+		return parseWideCSV(rows[1:], true)
+	}
+
+	groupCol := -1
+	groupIsCycle := false
+	if c, ok := columns["cycle_count"]; ok {
+		groupCol = c
+		groupIsCycle = true
+	} else if c, ok := columns["profile"]; ok {
+		groupCol = c
+	}
+
+	var profiles []recordedProfile
+	var lastGroup string
+	for i, row := range rows[1:] {
+		lineNum := i + 2
+		if forceCol >= len(row) {
+			return nil, fmt.Errorf("row %d: missing force column", lineNum)
+		}
+		force, err := strconv.ParseFloat(strings.TrimSpace(row[forceCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: force %q is not numeric", lineNum, row[forceCol])
+		}
+
+		group := ""
+		if groupCol >= 0 && groupCol < len(row) {
+			group = strings.TrimSpace(row[groupCol])
+		}
+		if len(profiles) == 0 || group != lastGroup {
+			cycle := 0
+			if groupIsCycle {
+				cycle, _ = strconv.Atoi(group)
+			}
+			profiles = append(profiles, recordedProfile{cycleCount: cycle})
+			lastGroup = group
+		}
+		last := &profiles[len(profiles)-1]
+		last.samples = append(last.samples, force)
+	}
+	return profiles, nil
+}
+
+func parseWideCSV(rows [][]string, cycleFirst bool) ([]recordedProfile, error) {
+	var profiles []recordedProfile
+	for i, row := range rows {
+		var profile recordedProfile
+		for j, cell := range row {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue // ragged rows: shorter profiles leave trailing cells empty
+			}
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("profile %d column %d: %q is not numeric", i+1, j+1, cell)
+			}
+			if cycleFirst && j == 0 {
+				profile.cycleCount = int(v)
+				continue
+			}
+			profile.samples = append(profile.samples, v)
+		}
+		if len(profile.samples) > 0 {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,203 @@
+package kettlecycletest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func writeReplayFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing replay file: %v", err)
+	}
+	return path
+}
+
+func TestLoadReplayProfiles(t *testing.T) {
+	t.Run("JSONL force-sensor readings", func(t *testing.T) {
+		path := writeReplayFile(t, "captures.jsonl", strings.Join([]string{
+			`{"cycle_count": 1, "samples": [50, 120, 200]}`,
+			``,
+			`{"data": {"readings": {"cycle_count": 2, "samples": [60, "130.5"]}}}`,
+		}, "\n"))
+		profiles, err := loadReplayProfiles(path)
+		if err != nil {
+			t.Fatalf("loadReplayProfiles failed: %v", err)
+		}
+		if len(profiles) != 2 {
+			t.Fatalf("expected 2 profiles, got %d", len(profiles))
+		}
+		if profiles[1].cycleCount != 2 || profiles[1].samples[1] != 130.5 {
+			t.Errorf("unexpected second profile: %+v", profiles[1])
+		}
+	})
+
+	t.Run("long CSV grouped by cycle_count", func(t *testing.T) {
+		path := writeReplayFile(t, "captures.csv",
+			"cycle_count,sample,force\n3,0,10\n3,1,20\n4,0,15\n4,1,25\n4,2,35\n")
+		profiles, err := loadReplayProfiles(path)
+		if err != nil {
+			t.Fatalf("loadReplayProfiles failed: %v", err)
+		}
+		if len(profiles) != 2 || profiles[0].cycleCount != 3 || len(profiles[1].samples) != 3 {
+			t.Errorf("unexpected profiles: %+v", profiles)
+		}
+	})
+
+	t.Run("wide CSV with and without header", func(t *testing.T) {
+		path := writeReplayFile(t, "wide.csv", "cycle_count,s0,s1,s2\n7,1,2,3\n8,4,5,\n")
+		profiles, err := loadReplayProfiles(path)
+		if err != nil {
+			t.Fatalf("loadReplayProfiles failed: %v", err)
+		}
+		if len(profiles) != 2 || profiles[0].cycleCount != 7 || len(profiles[1].samples) != 2 {
+			t.Errorf("unexpected profiles: %+v", profiles)
+		}
+
+		path = writeReplayFile(t, "bare.csv", "1,2,3\n4,5,6\n")
+		profiles, err = loadReplayProfiles(path)
+		if err != nil {
+			t.Fatalf("loadReplayProfiles failed: %v", err)
+		}
+		if len(profiles) != 2 || profiles[1].samples[2] != 6 {
+			t.Errorf("unexpected profiles: %+v", profiles)
+		}
+	})
+
+	t.Run("rejects bad input", func(t *testing.T) {
+		cases := map[string]string{
+			"empty.jsonl":  "",
+			"bad.jsonl":    `{"samples": [1, "x"]}`,
+			"nosamp.jsonl": `{"max_force": 3}`,
+			"bad.csv":      "cycle_count,force\n1,abc\n",
+			"data.txt":     "1,2,3",
+		}
+		for name, contents := range cases {
+			if _, err := loadReplayProfiles(writeReplayFile(t, name, contents)); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestReplayForceReader(t *testing.T) {
+	profiles := []recordedProfile{
+		{cycleCount: 10, samples: []float64{1, 2}},
+		{cycleCount: 11, samples: []float64{5, 6, 7}},
+	}
+	ctx := context.Background()
+
+	t.Run("plays one profile per capture and holds the last sample", func(t *testing.T) {
+		r := newReplayForceReader(profiles, false, false)
+		if f, _ := r.ReadForce(ctx); f != 0 {
+			t.Errorf("expected 0 while lifted, got %v", f)
+		}
+
+		r.captureStarted(0)
+		var got []float64
+		for i := 0; i < 3; i++ {
+			f, _ := r.ReadForce(ctx)
+			got = append(got, f)
+		}
+		if got[0] != 1 || got[1] != 2 || got[2] != 2 {
+			t.Errorf("first capture = %v, want [1 2 2]", got)
+		}
+		r.captureEnded()
+
+		r.captureStarted(0)
+		if f, _ := r.ReadForce(ctx); f != 5 {
+			t.Errorf("second capture should start with 5, got %v", f)
+		}
+		r.captureEnded()
+
+		r.captureStarted(0)
+		if _, err := r.ReadForce(ctx); err == nil {
+			t.Error("expected error once all profiles played without looping")
+		}
+	})
+
+	t.Run("loops", func(t *testing.T) {
+		r := newReplayForceReader(profiles, true, false)
+		for i := 0; i < 2; i++ {
+			r.captureStarted(0)
+			r.captureEnded()
+		}
+		r.captureStarted(0)
+		if f, _ := r.ReadForce(ctx); f != 1 {
+			t.Errorf("expected playback to wrap to first profile, got %v", f)
+		}
+	})
+
+	t.Run("selects by cycle number", func(t *testing.T) {
+		r := newReplayForceReader(profiles, false, true)
+		r.captureStarted(11)
+		if f, _ := r.ReadForce(ctx); f != 5 {
+			t.Errorf("cycle 11 should play the profile recorded for cycle 11, got %v", f)
+		}
+		r.captureStarted(1)
+		if f, _ := r.ReadForce(ctx); f != 1 {
+			t.Errorf("cycle 1 should fall back to the first profile, got %v", f)
+		}
+		r.captureStarted(3)
+		if _, err := r.ReadForce(ctx); err == nil {
+			t.Error("expected error for cycle beyond recorded profiles")
+		}
+	})
+}
+
+func TestForceSensor_ReplayCapture(t *testing.T) {
+	reader := newReplayForceReader([]recordedProfile{{samples: []float64{2, 40, 180, 150}}}, false, false)
+	fs := &forceSensor{
+		name:           resource.NewName(resource.APINamespaceRDK.WithComponentType("sensor"), "test"),
+		logger:         logging.NewTestLogger(t),
+		reader:         reader,
+		replay:         reader,
+		sampleRateHz:   200,
+		bufferSize:     100,
+		zeroThreshold:  5.0,
+		captureTimeout: 10 * time.Second,
+		ring:           newSampleRing(100, 0),
+	}
+	fs.startSampling()
+	defer fs.Close(context.Background())
+
+	fs.handleStartCapture(map[string]interface{}{})
+	time.Sleep(50 * time.Millisecond)
+
+	result, err := fs.handleEndCapture()
+	if err != nil {
+		t.Fatalf("handleEndCapture failed: %v", err)
+	}
+	if result["max_force"] != 180.0 {
+		t.Errorf("expected replayed max_force=180, got %v", result["max_force"])
+	}
+}
+
+func TestForceSensorConfig_Replay(t *testing.T) {
+	cfg := &ForceSensorConfig{ReplayFile: "/data/captures.jsonl"}
+	deps, _, err := cfg.Validate("test")
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("replay needs no dependencies, got %v", deps)
+	}
+
+	cfg.LoadCell = "adc"
+	if _, _, err := cfg.Validate("test"); err == nil {
+		t.Error("expected error when replay_file combined with load_cell")
+	}
+
+	cfg = &ForceSensorConfig{LoadCell: "adc", ReplayLoop: true}
+	if _, _, err := cfg.Validate("test"); err == nil {
+		t.Error("expected error for replay_loop without replay_file")
+	}
+}
@@ -0,0 +1,105 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Resting weight fault reasons.
+const (
+	faultKettleMissing    = "kettle_missing"    // nearly no weight on the load cell before lift
+	faultUnexpectedWeight = "unexpected_weight" // weight outside the expected band
+)
+
+// RestingWeightConfig enables a pre-lift check of the kettle's weight on the
+// force sensor.
+type RestingWeightConfig struct {
+	Expected     float64 `json:"expected"`                // resting kettle weight in force sensor units
+	Tolerance    float64 `json:"tolerance,omitempty"`     // allowed deviation from expected (default: 10% of it)
+	MissingBelow float64 `json:"missing_below,omitempty"` // weight under which the kettle counts as missing (default: 25% of expected)
+	Samples      int     `json:"samples,omitempty"`       // reads averaged per check (default: 5)
+}
+
+func (cfg *RestingWeightConfig) validate() error {
+	if cfg.Expected <= 0 {
+		return fmt.Errorf("expected must be positive")
+	}
+	if cfg.Tolerance < 0 {
+		return fmt.Errorf("tolerance must not be negative")
+	}
+	if cfg.MissingBelow < 0 {
+		return fmt.Errorf("missing_below must not be negative")
+	}
+	if cfg.Samples < 0 {
+		return fmt.Errorf("samples must not be negative")
+	}
+	return nil
+}
+
+func (cfg RestingWeightConfig) withDefaults() RestingWeightConfig {
+	if cfg.Tolerance == 0 {
+		cfg.Tolerance = 0.1 * cfg.Expected
+	}
+	if cfg.MissingBelow == 0 {
+		cfg.MissingBelow = 0.25 * cfg.Expected
+	}
+	if cfg.Samples == 0 {
+		cfg.Samples = 5
+	}
+	return cfg
+}
+
+// check returns the fault reason for a resting weight, or "" when it is
+// within the band.
+func (cfg RestingWeightConfig) check(weight float64) string {
+	cfg = cfg.withDefaults()
+	if weight < cfg.MissingBelow {
+		return faultKettleMissing
+	}
+	if math.Abs(weight-cfg.Expected) > cfg.Tolerance {
+		return faultUnexpectedWeight
+	}
+	return ""
+}
+
+// readForce averages samples reads from the force sensor outside a capture.
+func (s *kettleCycleTestController) readForce(ctx context.Context, samples int) (float64, error) {
+	resp, err := s.forceSensor.DoCommand(ctx, map[string]interface{}{"command": "read_force", "samples": samples})
+	if err != nil {
+		return 0, err
+	}
+	force, ok := toFloat64(resp["force"])
+	if !ok {
+		return 0, fmt.Errorf("read_force returned no force: %v", resp)
+	}
+	return force, nil
+}
+
+// checkRestingWeight reads the kettle's weight before lift and records it
+// in the result and the trial. It returns the fault reason, if any.
+func (s *kettleCycleTestController) checkRestingWeight(ctx context.Context, result map[string]interface{}) (string, error) {
+	cfg := s.cfg.RestingWeight.withDefaults()
+	weight, err := s.readForce(ctx, cfg.Samples)
+	if err != nil {
+		return "", fmt.Errorf("reading resting weight: %w", err)
+	}
+	result["resting_weight"] = weight
+
+	s.mu.Lock()
+	if trial := s.cycleTrialLocked(); trial != nil {
+		if !trial.hasRestingWeight {
+			trial.firstRestingWeight = weight
+			trial.hasRestingWeight = true
+		}
+		trial.lastRestingWeight = weight
+	}
+	s.mu.Unlock()
+
+	reason := cfg.check(weight)
+	if reason != "" {
+		s.logger.Warnf("resting weight %.2f outside %.2f ± %.2f (missing below %.2f)",
+			weight, cfg.Expected, cfg.Tolerance, cfg.MissingBelow)
+	}
+	return reason, nil
+}
@@ -0,0 +1,132 @@
+package kettlecycletest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestRestingWeightConfig(t *testing.T) {
+	cfg := RestingWeightConfig{Expected: 20}
+	cases := map[float64]string{
+		20:   "",
+		21.5: "",
+		3:    faultKettleMissing,
+		17:   faultUnexpectedWeight,
+		25:   faultUnexpectedWeight,
+	}
+	for weight, want := range cases {
+		if got := cfg.check(weight); got != want {
+			t.Errorf("check(%v) = %q, want %q", weight, got, want)
+		}
+	}
+
+	if err := (&RestingWeightConfig{}).validate(); err == nil {
+		t.Error("expected error without expected weight")
+	}
+	c := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", RestingWeight: &cfg}
+	if _, _, err := c.Validate("test"); err == nil {
+		t.Error("expected error for resting_weight without force_sensor")
+	}
+}
+
+func TestForceSensor_ReadForce(t *testing.T) {
+	fs := newTestForceSensor(t)
+
+	result, err := fs.DoCommand(context.Background(), map[string]interface{}{"command": "read_force", "samples": 3})
+	if err != nil {
+		t.Fatalf("read_force failed: %v", err)
+	}
+	if result["samples"] != 3 {
+		t.Errorf("expected 3 samples, got %v", result["samples"])
+	}
+	if force, _ := toFloat64(result["force"]); force <= 0 {
+		t.Errorf("expected a positive force in contact, got %v", result["force"])
+	}
+
+	fs.setCaptureState(captureWaiting)
+	if _, err := fs.DoCommand(context.Background(), map[string]interface{}{"command": "read_force"}); err == nil {
+		t.Error("expected read_force to fail during a capture")
+	}
+}
+
+// newWeighingForceSensor returns a force sensor whose read_force reports the
+// next of weights on each call.
+func newWeighingForceSensor(weights ...float64) *inject.Sensor {
+	fs := inject.NewSensor("force")
+	fs.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "read_force" {
+			w := weights[0]
+			if len(weights) > 1 {
+				weights = weights[1:]
+			}
+			return map[string]interface{}{"force": w, "samples": cmd["samples"]}, nil
+		}
+		return map[string]interface{}{"status": "completed"}, nil
+	}
+	fs.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"capture_state": "idle"}, nil
+	}
+	return fs
+}
+
+func TestController_RestingWeight(t *testing.T) {
+	t.Run("missing kettle faults before lift", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.forceSensor = newWeighingForceSensor(0.5)
+		kctrl.cfg.RestingWeight = &RestingWeightConfig{Expected: 20}
+		lifted := false
+		pourPrep := inject.NewSwitch("pour-prep")
+		pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			lifted = true
+			return nil
+		}
+		kctrl.pourPrep = pourPrep
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("handleExecuteCycle failed: %v", err)
+		}
+		if result["status"] != "faulted" || result["fault"] != faultKettleMissing {
+			t.Errorf("expected kettle_missing fault, got %v", result)
+		}
+		if lifted {
+			t.Error("expected the arm not to lift a missing kettle")
+		}
+		state := kctrl.GetState()
+		if state["state"] != "faulted" || !strings.Contains(state["last_notification"].(string), faultKettleMissing) {
+			t.Errorf("expected faulted trial with notification, got %v", state)
+		}
+	})
+
+	t.Run("tracks weight change across cycles", func(t *testing.T) {
+		kctrl := newTestController(t)
+		kctrl.forceSensor = newWeighingForceSensor(20, 19.5)
+		kctrl.cfg.RestingWeight = &RestingWeightConfig{Expected: 20}
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		for i := 0; i < 2; i++ {
+			result, err := kctrl.handleExecuteCycle(context.Background())
+			if err != nil || result["status"] != "completed" {
+				t.Fatalf("expected completed cycle, got %v, %v", result, err)
+			}
+		}
+		state := kctrl.GetState()
+		if state["last_resting_weight"] != 19.5 || state["resting_weight_change"] != -0.5 {
+			t.Errorf("expected 0.5 weight loss, got %v", state)
+		}
+
+		history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+		cycles := history["cycles"].([]interface{})
+		if len(cycles) != 2 || cycles[1].(map[string]interface{})["resting_weight"] != 19.5 {
+			t.Errorf("expected resting weight in history, got %v", cycles)
+		}
+	})
+}
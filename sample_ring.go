@@ -0,0 +1,111 @@
+package kettlecycletest
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// sampleRing is a fixed, preallocated ring of force samples with a single
+// writer (the sampling goroutine). Slots are stored as atomic float bits so
+// snapshots can copy them without a lock; entries the writer overwrote while
+// a snapshot was copying are trimmed from the front of that snapshot.
+type sampleRing struct {
+	forces []atomic.Uint64 // math.Float64bits of the total force
+	cells  []atomic.Uint64 // per-cell forces, numCells per slot
+	size   uint64
+
+	numCells int
+	claimed  atomic.Uint64 // samples the writer has started; runs one ahead of written mid-push
+	written  atomic.Uint64 // samples ever pushed; slot of sample n is n % size
+	base     atomic.Uint64 // first sample of the current capture
+}
+
+func newSampleRing(size, numCells int) *sampleRing {
+	if size <= 0 {
+		size = 1
+	}
+	return &sampleRing{
+		forces:   make([]atomic.Uint64, size),
+		cells:    make([]atomic.Uint64, size*numCells),
+		size:     uint64(size),
+		numCells: numCells,
+	}
+}
+
+// push stores one sample, overwriting the oldest once the ring is full.
+// Only the sampling goroutine may call push. Cell forces are ignored when
+// the ring was built without cells.
+func (r *sampleRing) push(force float64, cellForces []float64) {
+	n := r.written.Load()
+	r.claimed.Store(n + 1)
+	slot := n % r.size
+	r.forces[slot].Store(math.Float64bits(force))
+	if r.numCells > 0 && len(cellForces) == r.numCells {
+		row := r.cells[int(slot)*r.numCells:]
+		for i, f := range cellForces {
+			row[i].Store(math.Float64bits(f))
+		}
+	}
+	r.written.Store(n + 1)
+}
+
+// reset empties the ring for a new capture without touching the slots.
+func (r *sampleRing) reset() {
+	r.base.Store(r.written.Load())
+}
+
+// bounds returns the range [from, to) of samples currently held.
+func (r *sampleRing) bounds() (uint64, uint64) {
+	to := r.written.Load()
+	from := r.base.Load()
+	if to > r.size && to-r.size > from {
+		from = to - r.size
+	}
+	if from > to {
+		from = to
+	}
+	return from, to
+}
+
+func (r *sampleRing) len() int {
+	from, to := r.bounds()
+	return int(to - from)
+}
+
+// snapshot copies the held samples, oldest first. cells is nil when the ring
+// has no cells; otherwise each row shares one backing array.
+func (r *sampleRing) snapshot() ([]float64, [][]float64) {
+	from, to := r.bounds()
+	forces := make([]float64, 0, to-from)
+	var flat []float64
+	if r.numCells > 0 {
+		flat = make([]float64, 0, int(to-from)*r.numCells)
+	}
+	for n := from; n < to; n++ {
+		slot := n % r.size
+		forces = append(forces, math.Float64frombits(r.forces[slot].Load()))
+		if r.numCells > 0 {
+			row := r.cells[int(slot)*r.numCells : int(slot+1)*r.numCells]
+			for i := range row {
+				flat = append(flat, math.Float64frombits(row[i].Load()))
+			}
+		}
+	}
+
+	// The writer may have lapped us mid-copy. Every sample older than
+	// claimed-size has been (or is being) overwritten, so drop those.
+	if c := r.claimed.Load(); c > r.size && c-r.size > from {
+		skip := min(c-r.size-from, to-from)
+		forces = forces[skip:]
+		flat = flat[int(skip)*r.numCells:]
+	}
+
+	if r.numCells == 0 {
+		return forces, nil
+	}
+	cells := make([][]float64, len(forces))
+	for i := range cells {
+		cells[i] = flat[i*r.numCells : (i+1)*r.numCells : (i+1)*r.numCells]
+	}
+	return forces, cells
+}
@@ -0,0 +1,203 @@
+package kettlecycletest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampleRing(t *testing.T) {
+	t.Run("keeps the newest samples once full", func(t *testing.T) {
+		r := newSampleRing(3, 0)
+		for i := 1; i <= 5; i++ {
+			r.push(float64(i), nil)
+		}
+		forces, cells := r.snapshot()
+		if len(forces) != 3 || forces[0] != 3 || forces[2] != 5 {
+			t.Errorf("snapshot = %v, want [3 4 5]", forces)
+		}
+		if cells != nil {
+			t.Errorf("expected no cell rows, got %v", cells)
+		}
+	})
+
+	t.Run("reset starts an empty capture", func(t *testing.T) {
+		r := newSampleRing(4, 0)
+		r.push(1, nil)
+		r.push(2, nil)
+		r.reset()
+		if r.len() != 0 {
+			t.Errorf("expected empty ring after reset, got %d samples", r.len())
+		}
+		r.push(3, nil)
+		if forces, _ := r.snapshot(); len(forces) != 1 || forces[0] != 3 {
+			t.Errorf("snapshot after reset = %v, want [3]", forces)
+		}
+	})
+
+	t.Run("stores cell rows alongside totals", func(t *testing.T) {
+		r := newSampleRing(2, 2)
+		r.push(3, []float64{1, 2})
+		r.push(7, []float64{3, 4})
+		r.push(11, []float64{5, 6})
+		forces, cells := r.snapshot()
+		if len(cells) != 2 || cells[0][0] != 3 || cells[1][1] != 6 || forces[1] != 11 {
+			t.Errorf("snapshot = %v %v, want [7 11] [[3 4] [5 6]]", forces, cells)
+		}
+	})
+
+	t.Run("snapshots stay consistent while the writer laps them", func(t *testing.T) {
+		r := newSampleRing(8, 1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100000; i++ {
+				f := float64(i)
+				r.push(f, []float64{f})
+			}
+		}()
+
+		for {
+			forces, cells := r.snapshot()
+			if len(forces) > 8 {
+				t.Fatalf("snapshot longer than the ring: %d", len(forces))
+			}
+			for i := range forces {
+				if cells[i][0] != forces[i] {
+					t.Fatalf("torn sample: total %v, cell %v", forces[i], cells[i][0])
+				}
+				if i > 0 && forces[i] != forces[i-1]+1 {
+					t.Fatalf("snapshot not consecutive: %v", forces)
+				}
+			}
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	})
+}
+
+func TestForceSensor_DroppedTicks(t *testing.T) {
+	// Reads take ~4 sample periods, so the ticker discards the ticks in between
+	fs := newTestForceSensor(t)
+	fs.reader = &slowForceReader{delay: 40 * time.Millisecond}
+	fs.readTimeout = time.Second
+	fs.startSampling()
+	defer fs.Close(context.Background())
+
+	fs.handleStartCapture(map[string]interface{}{})
+	time.Sleep(300 * time.Millisecond)
+
+	readings, _ := fs.Readings(context.Background(), nil)
+	if readings["dropped_ticks"].(int64) == 0 {
+		t.Error("expected dropped ticks while reads overrun the sample period")
+	}
+	result, _ := fs.handleEndCapture()
+	if result["dropped_ticks"].(int64) == 0 {
+		t.Error("expected dropped_ticks in end_capture result")
+	}
+
+	fs.handleStartCapture(map[string]interface{}{})
+	readings, _ = fs.Readings(context.Background(), nil)
+	if readings["dropped_ticks"].(int64) != 0 {
+		t.Error("expected dropped_ticks to reset with each capture")
+	}
+	fs.handleEndCapture()
+}
+
+type slowForceReader struct {
+	delay time.Duration
+}
+
+func (r *slowForceReader) ReadForce(ctx context.Context) (float64, error) {
+	time.Sleep(r.delay)
+	return 100, nil
+}
+
+func BenchmarkSampleRing_Push(b *testing.B) {
+	r := newSampleRing(1000, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.push(float64(i), nil)
+	}
+}
+
+func BenchmarkSampleRing_PushCells(b *testing.B) {
+	r := newSampleRing(1000, 4)
+	cells := []float64{1, 2, 3, 4}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.push(float64(i), cells)
+	}
+}
+
+// BenchmarkSampleRing_PushWhileReading measures writer throughput with a
+// reader snapshotting continuously, as Readings does during data capture.
+func BenchmarkSampleRing_PushWhileReading(b *testing.B) {
+	r := newSampleRing(1000, 0)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				r.snapshot()
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.push(float64(i), nil)
+	}
+	b.StopTimer()
+	close(stop)
+	wg.Wait()
+}
+
+func BenchmarkSampleRing_Snapshot(b *testing.B) {
+	r := newSampleRing(1000, 0)
+	for i := 0; i < 1000; i++ {
+		r.push(float64(i), nil)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.snapshot()
+	}
+}
+
+// BenchmarkForceSensor_Sample measures one sampling tick's work: a read
+// through the worker goroutine plus the ring push.
+func BenchmarkForceSensor_Sample(b *testing.B) {
+	fs := &forceSensor{
+		reader:       &blockingForceReader{},
+		sampleRateHz: 1000,
+		ring:         newSampleRing(1000, 0),
+		readTimeout:  time.Second,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fs.readReq = make(chan struct{}, 1)
+	fs.readRes = make(chan sampleResult, 1)
+	go fs.runReadWorker(ctx)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		force, cells, err := fs.readWithTimeout(ctx, timer)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fs.ring.push(force, cells)
+	}
+}
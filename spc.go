@@ -0,0 +1,405 @@
+package kettlecycletest
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metrics tracked by statistical process control.
+const (
+	spcMaxForce      = "max_force"      // peak put-down force
+	spcCycleDuration = "cycle_duration" // seconds per cycle
+	spcImpulse       = "impulse"        // integral of force over the put-down
+)
+
+var spcMetrics = []string{spcMaxForce, spcCycleDuration, spcImpulse}
+
+// Rules reported as SPC violations.
+const (
+	ruleBeyond3Sigma = "beyond_3_sigma"        // Western Electric 1
+	rule2Of3Beyond2  = "2_of_3_beyond_2_sigma" // Western Electric 2
+	rule4Of5Beyond1  = "4_of_5_beyond_1_sigma" // Western Electric 3
+	rule8SameSide    = "8_on_one_side"         // Western Electric 4
+	ruleRangeHigh    = "range_above_ucl"       // X-bar/R range chart
+	ruleCUSUMHigh    = "cusum_high"
+	ruleCUSUMLow     = "cusum_low"
+	ruleEWMAHigh     = "ewma_high"
+	ruleEWMALow      = "ewma_low"
+)
+
+// Control chart constants by subgroup size (index n).
+var (
+	spcD2 = []float64{0, 0, 1.128, 1.693, 2.059, 2.326, 2.534, 2.704, 2.847, 2.970, 3.078}
+	spcD4 = []float64{0, 0, 3.267, 2.574, 2.282, 2.114, 2.004, 1.924, 1.864, 1.816, 1.777}
+)
+
+// SPCLimits fixes a chart's center line and sigma instead of learning them.
+// Sigma is the standard deviation of a plotted point (a subgroup mean when
+// subgroup_size > 1).
+type SPCLimits struct {
+	Center float64 `json:"center"`
+	Sigma  float64 `json:"sigma"`
+}
+
+// SPCConfig enables control charts on per-cycle metrics.
+type SPCConfig struct {
+	Metrics      []string             `json:"metrics,omitempty"`       // default: max_force, cycle_duration, impulse
+	SubgroupSize int                  `json:"subgroup_size,omitempty"` // 1 (default) for individuals charts, 2-10 for X-bar/R
+	WarmupPoints int                  `json:"warmup_points,omitempty"` // plotted points that set limits (default: 20)
+	Limits       map[string]SPCLimits `json:"limits,omitempty"`        // fixed limits per metric, skipping warm-up
+
+	CUSUMK     float64 `json:"cusum_k,omitempty"`     // allowance in sigmas (default: 0.5)
+	CUSUMH     float64 `json:"cusum_h,omitempty"`     // decision interval in sigmas (default: 5)
+	EWMALambda float64 `json:"ewma_lambda,omitempty"` // smoothing weight (default: 0.2)
+	EWMALWidth float64 `json:"ewma_l,omitempty"`      // limit width in sigmas (default: 3)
+}
+
+func (cfg *SPCConfig) validate() error {
+	for _, m := range cfg.Metrics {
+		if !isSPCMetric(m) {
+			return fmt.Errorf("unknown metric %q (must be one of %v)", m, spcMetrics)
+		}
+	}
+	for m, limits := range cfg.Limits {
+		if !isSPCMetric(m) {
+			return fmt.Errorf("limits: unknown metric %q", m)
+		}
+		if limits.Sigma <= 0 {
+			return fmt.Errorf("limits: %s: sigma must be positive", m)
+		}
+	}
+	if cfg.SubgroupSize < 0 || cfg.SubgroupSize >= len(spcD2) {
+		return fmt.Errorf("subgroup_size must be between 1 and %d", len(spcD2)-1)
+	}
+	if cfg.WarmupPoints < 0 {
+		return fmt.Errorf("warmup_points must not be negative")
+	}
+	if cfg.WarmupPoints == 1 {
+		return fmt.Errorf("warmup_points must be at least 2")
+	}
+	if cfg.CUSUMK < 0 || cfg.CUSUMH < 0 || cfg.EWMALWidth < 0 {
+		return fmt.Errorf("cusum_k, cusum_h and ewma_l must not be negative")
+	}
+	if cfg.EWMALambda < 0 || cfg.EWMALambda > 1 {
+		return fmt.Errorf("ewma_lambda must be between 0 and 1")
+	}
+	return nil
+}
+
+func (cfg SPCConfig) withDefaults() SPCConfig {
+	if len(cfg.Metrics) == 0 {
+		cfg.Metrics = spcMetrics
+	}
+	if cfg.SubgroupSize == 0 {
+		cfg.SubgroupSize = 1
+	}
+	if cfg.WarmupPoints == 0 {
+		cfg.WarmupPoints = 20
+	}
+	if cfg.CUSUMK == 0 {
+		cfg.CUSUMK = 0.5
+	}
+	if cfg.CUSUMH == 0 {
+		cfg.CUSUMH = 5
+	}
+	if cfg.EWMALambda == 0 {
+		cfg.EWMALambda = 0.2
+	}
+	if cfg.EWMALWidth == 0 {
+		cfg.EWMALWidth = 3
+	}
+	return cfg
+}
+
+// needsForce reports whether any charted metric comes from the force sensor.
+func (cfg SPCConfig) needsForce() bool {
+	for _, m := range cfg.withDefaults().Metrics {
+		if m != spcCycleDuration {
+			return true
+		}
+	}
+	return false
+}
+
+func isSPCMetric(m string) bool {
+	for _, known := range spcMetrics {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// spcViolation is one rule broken by one plotted point.
+type spcViolation struct {
+	metric string
+	rule   string
+	value  float64 // the plotted point (subgroup mean for X-bar)
+}
+
+func (v spcViolation) String() string {
+	return fmt.Sprintf("%s:%s", v.metric, v.rule)
+}
+
+// spcChart is an individuals (I-MR) or X-bar/R chart for one metric, with
+// Western Electric rules, a tabular CUSUM and an EWMA on the same points.
+type spcChart struct {
+	metric string
+	cfg    SPCConfig
+
+	// Limits, learned from warm-up unless fixed in config
+	ready  bool
+	center float64
+	sigma  float64 // of a plotted point
+	rBar   float64 // mean range (X-bar/R only)
+
+	subgroup     []float64
+	warmup       []float64 // plotted points collected for limits
+	warmupRanges []float64 // subgroup ranges or moving ranges
+
+	prev    float64 // previous individual, for moving ranges
+	hasPrev bool
+
+	recentZ  []float64 // last 8 standardized points
+	cusumHi  float64
+	cusumLo  float64
+	ewma     float64
+	ewmaN    int
+	lastMean float64
+	plotted  bool
+}
+
+func newSPCChart(metric string, cfg SPCConfig) *spcChart {
+	c := &spcChart{metric: metric, cfg: cfg}
+	if limits, ok := cfg.Limits[metric]; ok {
+		c.setLimits(limits.Center, limits.Sigma)
+	}
+	return c
+}
+
+func (c *spcChart) setLimits(center, sigma float64) {
+	c.center = center
+	c.sigma = sigma
+	c.ewma = center
+	c.ready = true
+}
+
+// add records one cycle's value and returns the violations of any point
+// it completed.
+func (c *spcChart) add(value float64) []spcViolation {
+	c.subgroup = append(c.subgroup, value)
+	if len(c.subgroup) < c.cfg.SubgroupSize {
+		return nil
+	}
+	mean, rng := subgroupStats(c.subgroup)
+	c.subgroup = c.subgroup[:0]
+
+	if c.cfg.SubgroupSize == 1 {
+		if c.hasPrev {
+			rng = math.Abs(value - c.prev)
+		} else {
+			rng = math.NaN()
+		}
+		c.prev, c.hasPrev = value, true
+	}
+	c.lastMean = mean
+	c.plotted = true
+
+	if !c.ready {
+		c.learn(mean, rng)
+		return nil
+	}
+	return c.check(mean, rng)
+}
+
+// learn collects warm-up points and sets limits once there are enough.
+func (c *spcChart) learn(mean, rng float64) {
+	c.warmup = append(c.warmup, mean)
+	if !math.IsNaN(rng) {
+		c.warmupRanges = append(c.warmupRanges, rng)
+	}
+	if len(c.warmup) < c.cfg.WarmupPoints {
+		return
+	}
+
+	center := meanOf(c.warmup)
+	rBar := meanOf(c.warmupRanges)
+	n := c.cfg.SubgroupSize
+	var sigma float64
+	if n == 1 {
+		sigma = rBar / spcD2[2]
+	} else {
+		sigma = rBar / (spcD2[n] * math.Sqrt(float64(n)))
+		c.rBar = rBar
+	}
+	c.setLimits(center, sigma)
+	c.warmup, c.warmupRanges = nil, nil
+}
+
+func (c *spcChart) check(mean, rng float64) []spcViolation {
+	if c.sigma <= 0 {
+		// A perfectly constant warm-up gives no spread to judge against
+		return nil
+	}
+	var violations []spcViolation
+	flag := func(rule string) {
+		violations = append(violations, spcViolation{metric: c.metric, rule: rule, value: mean})
+	}
+
+	z := (mean - c.center) / c.sigma
+	c.recentZ = append(c.recentZ, z)
+	if len(c.recentZ) > 8 {
+		c.recentZ = c.recentZ[1:]
+	}
+	for _, rule := range westernElectric(c.recentZ) {
+		flag(rule)
+	}
+
+	if c.rBar > 0 && rng > spcD4[c.cfg.SubgroupSize]*c.rBar {
+		flag(ruleRangeHigh)
+	}
+
+	c.cusumHi = math.Max(0, c.cusumHi+z-c.cfg.CUSUMK)
+	c.cusumLo = math.Max(0, c.cusumLo-z-c.cfg.CUSUMK)
+	if c.cusumHi > c.cfg.CUSUMH {
+		flag(ruleCUSUMHigh)
+		c.cusumHi = 0
+	}
+	if c.cusumLo > c.cfg.CUSUMH {
+		flag(ruleCUSUMLow)
+		c.cusumLo = 0
+	}
+
+	lambda := c.cfg.EWMALambda
+	c.ewmaN++
+	c.ewma = lambda*mean + (1-lambda)*c.ewma
+	width := c.cfg.EWMALWidth * c.sigma *
+		math.Sqrt(lambda/(2-lambda)*(1-math.Pow(1-lambda, 2*float64(c.ewmaN))))
+	if c.ewma > c.center+width {
+		flag(ruleEWMAHigh)
+	} else if c.ewma < c.center-width {
+		flag(ruleEWMALow)
+	}
+	return violations
+}
+
+// westernElectric applies the four Western Electric zone rules to
+// standardized points, oldest first. Only patterns completed by the
+// newest point are reported.
+func westernElectric(zs []float64) []string {
+	last := zs[len(zs)-1]
+	var rules []string
+	if math.Abs(last) > 3 {
+		rules = append(rules, ruleBeyond3Sigma)
+	}
+	if beyondOnSide(zs, 3, 2, 2, last) {
+		rules = append(rules, rule2Of3Beyond2)
+	}
+	if beyondOnSide(zs, 5, 4, 1, last) {
+		rules = append(rules, rule4Of5Beyond1)
+	}
+	if len(zs) >= 8 && last != 0 {
+		sameSide := true
+		for _, z := range zs[len(zs)-8:] {
+			sameSide = sameSide && z*last > 0
+		}
+		if sameSide {
+			rules = append(rules, rule8SameSide)
+		}
+	}
+	return rules
+}
+
+// beyondOnSide reports whether at least need of the last window points lie
+// beyond limit sigmas on the newest point's side, the newest among them.
+func beyondOnSide(zs []float64, window, need int, limit, last float64) bool {
+	if len(zs) < window || math.Abs(last) <= limit {
+		return false
+	}
+	count := 0
+	for _, z := range zs[len(zs)-window:] {
+		if z*last > 0 && math.Abs(z) > limit {
+			count++
+		}
+	}
+	return count >= need
+}
+
+// state returns the chart's limits and latest point for GetState.
+func (c *spcChart) state() map[string]interface{} {
+	m := map[string]interface{}{"ready": c.ready}
+	if c.ready {
+		m["center"] = c.center
+		m["ucl"] = c.center + 3*c.sigma
+		m["lcl"] = c.center - 3*c.sigma
+	}
+	if c.plotted {
+		m["last"] = c.lastMean
+	}
+	return m
+}
+
+// spcMonitor charts every configured metric for one trial.
+type spcMonitor struct {
+	charts         []*spcChart
+	violationCount int
+	lastViolation  string
+}
+
+func newSPCMonitor(cfg SPCConfig) *spcMonitor {
+	cfg = cfg.withDefaults()
+	m := &spcMonitor{}
+	for _, metric := range cfg.Metrics {
+		m.charts = append(m.charts, newSPCChart(metric, cfg))
+	}
+	return m
+}
+
+// observe adds one cycle's metrics; metrics missing from values are skipped.
+func (m *spcMonitor) observe(values map[string]float64) []spcViolation {
+	var violations []spcViolation
+	for _, c := range m.charts {
+		v, ok := values[c.metric]
+		if !ok {
+			continue
+		}
+		violations = append(violations, c.add(v)...)
+	}
+	m.violationCount += len(violations)
+	if len(violations) > 0 {
+		m.lastViolation = violations[len(violations)-1].String()
+	}
+	return violations
+}
+
+func (m *spcMonitor) state() map[string]interface{} {
+	charts := make(map[string]interface{}, len(m.charts))
+	for _, c := range m.charts {
+		charts[c.metric] = c.state()
+	}
+	return map[string]interface{}{
+		"spc":                charts,
+		"spc_violations":     m.violationCount,
+		"last_spc_violation": m.lastViolation,
+	}
+}
+
+func subgroupStats(values []float64) (mean, rng float64) {
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return meanOf(values), hi - lo
+}
+
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,185 @@
+package kettlecycletest
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func violationRules(vs []spcViolation) []string {
+	rules := make([]string, len(vs))
+	for i, v := range vs {
+		rules[i] = v.rule
+	}
+	return rules
+}
+
+func TestWesternElectric(t *testing.T) {
+	cases := []struct {
+		name string
+		zs   []float64
+		want string
+	}{
+		{"one point beyond 3 sigma", []float64{0.1, -3.2}, ruleBeyond3Sigma},
+		{"2 of 3 beyond 2 sigma", []float64{2.5, 0.3, 2.1}, rule2Of3Beyond2},
+		{"4 of 5 beyond 1 sigma", []float64{1.5, 1.2, 0.2, 1.1, 1.4}, rule4Of5Beyond1},
+		{"8 on one side", []float64{0.2, 0.5, 0.1, 0.9, 0.3, 0.4, 0.2, 0.6}, rule8SameSide},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := westernElectric(tc.zs)
+			if !hasFlag(rules, tc.want) {
+				t.Errorf("westernElectric(%v) = %v, want %s", tc.zs, rules, tc.want)
+			}
+		})
+	}
+
+	if rules := westernElectric([]float64{2.5, -2.5, 0.5}); len(rules) != 0 {
+		t.Errorf("expected no violations, got %v", rules)
+	}
+	// Pattern must be completed by the newest point
+	if rules := westernElectric([]float64{2.5, 2.4, 0.1}); hasFlag(rules, rule2Of3Beyond2) {
+		t.Error("expected 2-of-3 to be reported only when the newest point completes it")
+	}
+}
+
+func TestSPCChart(t *testing.T) {
+	t.Run("individuals chart learns limits from warm-up", func(t *testing.T) {
+		c := newSPCChart(spcMaxForce, SPCConfig{WarmupPoints: 4}.withDefaults())
+		for _, v := range []float64{100, 102, 100, 102} {
+			if vs := c.add(v); vs != nil {
+				t.Fatalf("expected no violations during warm-up, got %v", vs)
+			}
+		}
+		if !c.ready || c.center != 101 {
+			t.Fatalf("expected limits centered at 101, got ready=%v center=%v", c.ready, c.center)
+		}
+		// Moving range 2 -> sigma = 2/1.128
+		if vs := c.add(110); !hasFlag(violationRules(vs), ruleBeyond3Sigma) {
+			t.Errorf("expected beyond_3_sigma for 110, got %v", violationRules(vs))
+		}
+	})
+
+	t.Run("CUSUM catches a small sustained shift", func(t *testing.T) {
+		cfg := SPCConfig{Limits: map[string]SPCLimits{spcImpulse: {Center: 10, Sigma: 1}}}.withDefaults()
+		c := newSPCChart(spcImpulse, cfg)
+		var rules []string
+		for i := 0; i < 10 && !hasFlag(rules, ruleCUSUMHigh); i++ {
+			rules = violationRules(c.add(11.5))
+		}
+		if !hasFlag(rules, ruleCUSUMHigh) {
+			t.Error("expected cusum_high for a 1.5 sigma shift within 10 points")
+		}
+	})
+
+	t.Run("EWMA flags a drifting mean", func(t *testing.T) {
+		cfg := SPCConfig{Limits: map[string]SPCLimits{spcImpulse: {Center: 10, Sigma: 1}}}.withDefaults()
+		c := newSPCChart(spcImpulse, cfg)
+		seen := false
+		for i := 0; i < 10; i++ {
+			seen = seen || hasFlag(violationRules(c.add(8.5)), ruleEWMALow)
+		}
+		if !seen {
+			t.Error("expected ewma_low for a sustained low mean")
+		}
+	})
+
+	t.Run("X-bar/R chart plots subgroup means", func(t *testing.T) {
+		c := newSPCChart(spcCycleDuration, SPCConfig{SubgroupSize: 2, WarmupPoints: 2}.withDefaults())
+		for _, v := range []float64{10, 12, 11, 13} {
+			c.add(v)
+		}
+		if !c.ready || c.center != 11.5 || c.rBar != 2 {
+			t.Fatalf("expected center 11.5 and R-bar 2, got %v %v", c.center, c.rBar)
+		}
+		if vs := c.add(11); vs != nil {
+			t.Errorf("expected no point until the subgroup is full, got %v", vs)
+		}
+		if vs := c.add(20); !hasFlag(violationRules(vs), ruleRangeHigh) {
+			t.Errorf("expected range_above_ucl for range 9, got %v", violationRules(vs))
+		}
+	})
+}
+
+func TestSPCConfig(t *testing.T) {
+	bad := []SPCConfig{
+		{Metrics: []string{"temperature"}},
+		{SubgroupSize: 11},
+		{WarmupPoints: 1},
+		{Limits: map[string]SPCLimits{spcMaxForce: {Center: 1}}},
+		{EWMALambda: 2},
+	}
+	for _, cfg := range bad {
+		if err := cfg.validate(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+
+	cfg := &Config{Arm: "a", RestingPosition: "r", PourPrepPosition: "p", SPC: &SPCConfig{}}
+	if _, _, err := cfg.Validate("test"); err == nil {
+		t.Error("expected error for force metrics without force_sensor")
+	}
+	cfg.SPC.Metrics = []string{spcCycleDuration}
+	if _, _, err := cfg.Validate("test"); err != nil {
+		t.Errorf("cycle_duration alone needs no force sensor, got %v", err)
+	}
+}
+
+func TestController_SPCViolations(t *testing.T) {
+	kctrl := newTestController(t)
+	kctrl.cfg.SPC = &SPCConfig{
+		Metrics: []string{spcCycleDuration},
+		Limits:  map[string]SPCLimits{spcCycleDuration: {Center: 0.1, Sigma: 0.01}},
+	}
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{
+		trialID: "test-trial",
+		stopCh:  make(chan struct{}),
+		spc:     newSPCMonitor(*kctrl.cfg.SPC),
+	}
+	kctrl.mu.Unlock()
+
+	// A cycle takes over a second, far beyond the 0.1s center line
+	result, err := kctrl.handleExecuteCycle(context.Background())
+	if err != nil {
+		t.Fatalf("handleExecuteCycle failed: %v", err)
+	}
+	if !hasFlag(stringList(result["spc_violations"]), "cycle_duration:beyond_3_sigma") {
+		t.Errorf("expected cycle_duration violation, got %v", result["spc_violations"])
+	}
+
+	state := kctrl.GetState()
+	if state["spc_violations"] == 0 || state["last_spc_violation"] == "" {
+		t.Errorf("expected violations in status, got %v", state)
+	}
+	if !strings.Contains(state["last_notification"].(string), "spc_violation") {
+		t.Errorf("expected spc_violation notification, got %v", state["last_notification"])
+	}
+
+	history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history", "trial_id": "test-trial"})
+	cycles := history["cycles"].([]interface{})
+	if len(cycles) != 1 {
+		t.Fatalf("expected one cycle in history, got %v", cycles)
+	}
+	record := cycles[0].(map[string]interface{})
+	if len(stringList(record["spc_violations"])) == 0 || record["cycle_count"] != 1 {
+		t.Errorf("expected violations in the cycle record, got %v", record)
+	}
+}
+
+func TestCycleHistory(t *testing.T) {
+	h := newCycleHistory(3)
+	for i := 1; i <= 4; i++ {
+		trial := "a"
+		if i%2 == 0 {
+			trial = "b"
+		}
+		h.add(cycleRecord{trialID: trial, cycleCount: i})
+	}
+	if all := h.query("", 0); len(all) != 3 || all[0].(map[string]interface{})["cycle_count"] != 2 {
+		t.Errorf("expected the 3 newest records, got %v", all)
+	}
+	if b := h.query("b", 1); len(b) != 1 || b[0].(map[string]interface{})["cycle_count"] != 4 {
+		t.Errorf("expected newest record of trial b, got %v", b)
+	}
+}
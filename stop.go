@@ -0,0 +1,157 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// waitLoopDone waits for the trial's cycle loop to exit.
+func (t *trialState) waitLoopDone(ctx context.Context) error {
+	if t.loopDone == nil {
+		return nil
+	}
+	select {
+	case <-t.loopDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handleAbort cancels the in-flight cycle and stops the arm, ending the
+// active trial if there is one. With "safe_return" it then moves the arm
+// to resting, unless an interlock is latched.
+func (s *kettleCycleTestController) handleAbort(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	safeReturn, _ := cmd["safe_return"].(bool)
+
+	s.mu.Lock()
+	trial := s.activeTrial
+	if trial != nil {
+		trial.stop()
+	}
+	cancelCycle := s.cycleCancel
+	s.mu.Unlock()
+
+	if cancelCycle != nil {
+		cancelCycle()
+	}
+	dropped := s.executor.dropQueued()
+	if err := s.arm.Stop(ctx, nil); err != nil {
+		return nil, fmt.Errorf("stopping arm: %w", err)
+	}
+	if trial != nil {
+		if err := trial.waitLoopDone(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for the aborted cycle to exit: %w", err)
+		}
+	}
+	s.discardCapture(ctx)
+
+	result := map[string]interface{}{}
+	if trial != nil {
+		result = s.finishTrial(ctx, trial)
+	} else {
+		for k, v := range s.finalState(ctx) {
+			result[k] = v
+		}
+	}
+	result["aborted"] = true
+	if dropped > 0 {
+		result["dropped_commands"] = dropped
+	}
+
+	if !safeReturn {
+		return result, nil
+	}
+	if s.interlocked() {
+		result["safe_return"] = "skipped: interlocked"
+		return result, nil
+	}
+	// Ahead of anything queued, behind a manual command still winding down
+	if err := s.executor.acquire(ctx, "abort safe_return", true); err != nil {
+		return nil, fmt.Errorf("safe return waiting for the arm: %w", err)
+	}
+	defer s.executor.release()
+	if err := s.resting.SetPosition(ctx, 2, nil); err != nil {
+		return nil, fmt.Errorf("safe return to resting position: %w", err)
+	}
+	if err := s.waitForArmStopped(ctx); err != nil {
+		s.logger.Warnf("error waiting for arm to stop after safe return: %v", err)
+	}
+	result["safe_return"] = "completed"
+	for k, v := range s.finalState(ctx) {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// discardCapture ends a force capture an aborted cycle left open.
+func (s *kettleCycleTestController) discardCapture(ctx context.Context) {
+	if s.forceSensor == nil {
+		return
+	}
+	readings, err := s.forceSensor.Readings(ctx, nil)
+	if err != nil {
+		s.logger.Warnf("failed to read force capture state: %v", err)
+		return
+	}
+	if state, _ := readings["capture_state"].(string); state == "" || state == "idle" {
+		return
+	}
+	if _, err := s.forceSensor.DoCommand(ctx, map[string]interface{}{"command": "end_capture"}); err != nil {
+		s.logger.Warnf("failed to end aborted force capture: %v", err)
+	}
+}
+
+// finishTrial clears the trial, if it is still active, and reports its
+// final count together with the arm and force capture state.
+func (s *kettleCycleTestController) finishTrial(ctx context.Context, trial *trialState) map[string]interface{} {
+	s.mu.Lock()
+	if s.activeTrial == trial {
+		s.activeTrial = nil
+	}
+	result := map[string]interface{}{
+		"trial_id":    trial.trialID,
+		"cycle_count": trial.cycleCount,
+		"metadata":    trial.metadata.toMap(),
+	}
+	if trial.fault != "" {
+		result["fault"] = trial.fault
+	}
+	if len(trial.interlockEvents) > 0 {
+		result["interlock_events"] = trial.interlockEventList()
+	}
+	if len(trial.annotations) > 0 {
+		result["annotations"] = annotationList(trial.annotations)
+	}
+	s.events.add(event{at: time.Now(), kind: eventTrialStopped, trialID: trial.trialID,
+		specimenID: trial.metadata.fields[metaSpecimenID], cycleCount: trial.cycleCount,
+		message: fmt.Sprintf("trial %s stopped after %d cycles", trial.trialID, trial.cycleCount),
+		data:    map[string]interface{}{"fault": trial.fault}})
+	s.mu.Unlock()
+
+	for k, v := range s.finalState(ctx) {
+		result[k] = v
+	}
+	return result
+}
+
+// finalState reports whether the arm is moving and the force capture state.
+func (s *kettleCycleTestController) finalState(ctx context.Context) map[string]interface{} {
+	state := map[string]interface{}{}
+	if moving, err := s.arm.IsMoving(ctx); err != nil {
+		state["arm_error"] = err.Error()
+	} else {
+		state["arm_moving"] = moving
+	}
+
+	if s.forceSensor == nil {
+		return state
+	}
+	if readings, err := s.forceSensor.Readings(ctx, nil); err != nil {
+		state["force_error"] = err.Error()
+	} else {
+		state["force_capture_state"] = readings["capture_state"]
+	}
+	return state
+}
@@ -0,0 +1,135 @@
+package kettlecycletest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestController_GracefulStop(t *testing.T) {
+	kctrl := newTestController(t)
+	var moves atomic.Int32
+	resting := inject.NewSwitch("resting")
+	resting.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) { return 2, nil }
+	resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		moves.Add(1)
+		return nil
+	}
+	kctrl.resting = resting
+	lifting := make(chan struct{})
+	var lifts atomic.Int32
+	pourPrep := inject.NewSwitch("pour-prep")
+	pourPrep.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) { return 2, nil }
+	pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		if lifts.Add(1) == 1 {
+			close(lifting)
+		}
+		return nil
+	}
+	kctrl.pourPrep = pourPrep
+
+	if _, err := kctrl.handleStart(context.Background(), nil); err != nil {
+		t.Fatalf("handleStart failed: %v", err)
+	}
+	<-lifting
+	result, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "stop"})
+	if err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+
+	// The in-flight cycle finished before stop returned
+	if result["cycle_count"] != 1 || moves.Load() != 1 {
+		t.Errorf("expected one completed cycle, got count %v and %d returns to resting", result["cycle_count"], moves.Load())
+	}
+	if result["arm_moving"] != false {
+		t.Errorf("expected the arm at rest, got %v", result)
+	}
+	kctrl.mu.Lock()
+	inFlight := kctrl.cycleCancel != nil
+	kctrl.mu.Unlock()
+	if inFlight {
+		t.Error("expected no cycle in flight after stop")
+	}
+}
+
+func TestController_Abort(t *testing.T) {
+	kctrl := newTestController(t)
+	var stops atomic.Int32
+	testArm := inject.NewArm("test-arm")
+	testArm.IsMovingFunc = func(ctx context.Context) (bool, error) { return false, nil }
+	testArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		stops.Add(1)
+		return nil
+	}
+	kctrl.arm = testArm
+
+	moving := make(chan struct{})
+	var lifts atomic.Int32
+	pourPrep := inject.NewSwitch("pour-prep")
+	pourPrep.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) { return 2, nil }
+	pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		lifts.Add(1)
+		return nil
+	}
+	kctrl.pourPrep = pourPrep
+	// The put-down move blocks until cancelled, with the force capture open
+	var returns atomic.Int32
+	resting := inject.NewSwitch("resting")
+	resting.GetPositionFunc = func(ctx context.Context, extra map[string]interface{}) (uint32, error) { return 2, nil }
+	resting.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		if returns.Add(1) == 1 {
+			close(moving)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+	kctrl.resting = resting
+
+	var captureOpen, captureEnded atomic.Bool
+	force := inject.NewSensor("force")
+	force.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		switch cmd["command"] {
+		case "start_capture":
+			captureOpen.Store(true)
+		case "end_capture":
+			captureOpen.Store(false)
+			captureEnded.Store(true)
+		}
+		return map[string]interface{}{"force": 0.0}, nil
+	}
+	force.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		if captureOpen.Load() {
+			return map[string]interface{}{"capture_state": "waiting"}, nil
+		}
+		return map[string]interface{}{"capture_state": "idle"}, nil
+	}
+	kctrl.forceSensor = force
+
+	if _, err := kctrl.handleStart(context.Background(), nil); err != nil {
+		t.Fatalf("handleStart failed: %v", err)
+	}
+	<-moving
+
+	result, err := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "abort", "safe_return": true})
+	if err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	if stops.Load() != 1 {
+		t.Errorf("expected arm.Stop once, got %d", stops.Load())
+	}
+	if result["aborted"] != true || result["safe_return"] != "completed" || returns.Load() != 2 {
+		t.Errorf("expected aborted trial with safe return, got %v (%d returns)", result, returns.Load())
+	}
+	if !captureEnded.Load() || result["force_capture_state"] != "idle" {
+		t.Errorf("expected the open capture to be ended, got %v", result["force_capture_state"])
+	}
+	if kctrl.GetState()["state"] != "idle" {
+		t.Error("expected no active trial after abort")
+	}
+	if lifts.Load() != 1 {
+		t.Errorf("expected no cycle after the abort, got %d lifts", lifts.Load())
+	}
+}
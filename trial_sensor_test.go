@@ -103,20 +103,7 @@ func TestTrialSensor_ReadingsMatchesControllerState(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 
 	// 1. Create real controller with mock dependencies
-	testArm := inject.NewArm("test-arm")
-	testArm.IsMovingFunc = func(ctx context.Context) (bool, error) {
-		return false, nil
-	}
-	deps := resource.Dependencies{
-		resource.NewName(arm.API, "test-arm"):           testArm,
-		resource.NewName(toggleswitch.API, "resting"):   inject.NewSwitch("resting"),
-		resource.NewName(toggleswitch.API, "pour-prep"): inject.NewSwitch("pour-prep"),
-	}
-	cfg := &Config{
-		Arm:              "test-arm",
-		RestingPosition:  "resting",
-		PourPrepPosition: "pour-prep",
-	}
+	deps, cfg := testDeps()
 	ctrlName := resource.NewName(resource.APINamespaceRDK.WithServiceType("generic"), "test-controller")
 	ctrl, err := NewController(context.Background(), deps, ctrlName, cfg, logger)
 	if err != nil {
@@ -141,7 +128,7 @@ func TestTrialSensor_ReadingsMatchesControllerState(t *testing.T) {
 
 	// 2. Inject known state (start trial)
 	kctrl := ctrl.(*kettleCycleTestController)
-	kctrl.handleStart()
+	kctrl.handleStart(context.Background(), nil)
 
 	// 3. Call sensor.Readings()
 	readings, err := s.Readings(context.Background(), nil)
@@ -167,5 +154,5 @@ func TestTrialSensor_ReadingsMatchesControllerState(t *testing.T) {
 	}
 
 	// Cleanup
-	kctrl.handleStop()
+	kctrl.handleStop(context.Background())
 }
@@ -0,0 +1,219 @@
+package kettlecycletest
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Untimed steps the watchdog still tracks, so a hang names where it stuck.
+const (
+	phaseRestingWeight = "resting_weight_check"
+	phaseGrip          = "grip"
+	phasePoseCheck     = "pose_check"
+	phaseLiftCheck     = "lift_check"
+	phaseRelease       = "release"
+)
+
+// watchdogCycle is the expected_ms key for the whole cycle.
+const watchdogCycle = "cycle"
+
+// Fault reasons raised by the watchdog. A stall is reported as
+// faultStalledPrefix followed by the stuck phase, e.g. "stalled_upload".
+const (
+	faultStalledPrefix = "stalled_"
+	faultCyclePanic    = "cycle_panic"
+)
+
+const watchdogInterval = 100 * time.Millisecond
+
+// defaultExpectedMs is how long each phase and the whole cycle are expected
+// to take until the trial has learned its own averages.
+var defaultExpectedMs = map[string]int{
+	phaseMoveToPourPrep: 10000,
+	phaseSettle:         10000,
+	phaseImageCapture:   5000,
+	phaseUpload:         30000,
+	phaseForceCapture:   5000,
+	phaseReturn:         10000,
+	phaseDwell:          1000,
+	phaseRestingWeight:  5000,
+	phaseGrip:           5000,
+	phasePoseCheck:      5000,
+	phaseLiftCheck:      5000,
+	phaseRelease:        5000,
+	watchdogCycle:       60000,
+}
+
+// WatchdogConfig bounds how long a phase or cycle may run before the
+// watchdog cancels it and faults the trial.
+type WatchdogConfig struct {
+	Multiple   float64        `json:"multiple,omitempty"`     // allowed multiple of the expected duration (default: 3)
+	ExpectedMs map[string]int `json:"expected_ms,omitempty"`  // expected duration per phase or "cycle", overriding the defaults
+	MinLimitMs int            `json:"min_limit_ms,omitempty"` // floor for any limit, so fast phases don't trip on jitter (default: 2000)
+	LearnAfter int            `json:"learn_after,omitempty"`  // completed cycles before the trial's averages replace expected_ms (default: 5)
+}
+
+func (cfg *WatchdogConfig) validate() error {
+	if cfg.Multiple != 0 && cfg.Multiple <= 1 {
+		return fmt.Errorf("multiple must be greater than 1")
+	}
+	for phase, ms := range cfg.ExpectedMs {
+		if _, ok := defaultExpectedMs[phase]; !ok {
+			return fmt.Errorf("expected_ms: unknown phase %q", phase)
+		}
+		if ms <= 0 {
+			return fmt.Errorf("expected_ms: %s must be positive", phase)
+		}
+	}
+	if cfg.MinLimitMs < 0 {
+		return fmt.Errorf("min_limit_ms must not be negative")
+	}
+	if cfg.LearnAfter < 0 {
+		return fmt.Errorf("learn_after must not be negative")
+	}
+	return nil
+}
+
+func (cfg WatchdogConfig) withDefaults() WatchdogConfig {
+	if cfg.Multiple == 0 {
+		cfg.Multiple = 3
+	}
+	if cfg.MinLimitMs == 0 {
+		cfg.MinLimitMs = 2000
+	}
+	if cfg.LearnAfter == 0 {
+		cfg.LearnAfter = 5
+	}
+	return cfg
+}
+
+// cycleWatch is the in-flight cycle's progress as seen by the watchdog.
+type cycleWatch struct {
+	trialID        string
+	cycleCount     int
+	startedAt      time.Time
+	phase          string
+	phaseStartedAt time.Time
+	cancel         context.CancelFunc
+	manual         bool   // an operator-requested cycle, kept out of the active trial
+	tripped        string // fault reason once the watchdog has fired
+}
+
+// beginPhase records that the in-flight cycle entered a phase and returns
+// the phase's start time.
+func (s *kettleCycleTestController) beginPhase(phase string) time.Time {
+	now := time.Now()
+	s.mu.Lock()
+	if s.watch != nil {
+		s.watch.phase = phase
+		s.watch.phaseStartedAt = now
+	}
+	s.mu.Unlock()
+	return now
+}
+
+// currentPhaseLocked names the phase the in-flight cycle is in.
+func (s *kettleCycleTestController) currentPhaseLocked() string {
+	if s.watch == nil || s.watch.phase == "" {
+		return "start"
+	}
+	return s.watch.phase
+}
+
+// expectedLocked is the expected duration of a phase, or of the cycle for
+// watchdogCycle: the trial's rolling average once it has learned enough
+// cycles, otherwise the configured or default value.
+func (s *kettleCycleTestController) expectedLocked(cfg WatchdogConfig, phase string) time.Duration {
+	if s.activeTrial != nil && s.activeTrial.timing != nil && len(s.activeTrial.timing.durations) >= cfg.LearnAfter {
+		avg, ok := s.activeTrial.timing.phaseAverage(phase)
+		if phase == watchdogCycle {
+			avg, ok = s.activeTrial.timing.cycleAverage()
+		}
+		if ok {
+			return avg
+		}
+	}
+	if ms, ok := cfg.ExpectedMs[phase]; ok {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return time.Duration(defaultExpectedMs[phase]) * time.Millisecond
+}
+
+// limitLocked is how long a phase or the cycle may run.
+func (s *kettleCycleTestController) limitLocked(cfg WatchdogConfig, phase string) time.Duration {
+	limit := time.Duration(float64(s.expectedLocked(cfg, phase)) * cfg.Multiple)
+	return max(limit, time.Duration(cfg.MinLimitMs)*time.Millisecond)
+}
+
+// runWatchdog checks the in-flight cycle until ctx is cancelled.
+func (s *kettleCycleTestController) runWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkWatchdog(time.Now())
+		}
+	}
+}
+
+// checkWatchdog cancels a cycle whose phase or total time is over its
+// limit, faults the trial with the stuck phase, and alerts. A call that
+// ignores cancellation stays stuck, but the trial no longer reports running.
+func (s *kettleCycleTestController) checkWatchdog(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.watch
+	if w == nil || w.tripped != "" || s.cfg.Watchdog == nil {
+		return
+	}
+	cfg := s.cfg.Watchdog.withDefaults()
+
+	phase := s.currentPhaseLocked()
+	var detail string
+	if w.phase != "" {
+		if limit := s.limitLocked(cfg, w.phase); now.Sub(w.phaseStartedAt) > limit {
+			detail = fmt.Sprintf("phase %s running %v, limit %v", phase, now.Sub(w.phaseStartedAt).Round(time.Millisecond), limit.Round(time.Millisecond))
+		}
+	}
+	if detail == "" {
+		if limit := s.limitLocked(cfg, watchdogCycle); now.Sub(w.startedAt) > limit {
+			detail = fmt.Sprintf("cycle running %v in phase %s, limit %v", now.Sub(w.startedAt).Round(time.Millisecond), phase, limit.Round(time.Millisecond))
+		}
+	}
+	if detail == "" {
+		return
+	}
+
+	w.tripped = faultStalledPrefix + phase
+	w.cancel()
+	s.notifyLocked("cycle_stalled", fmt.Sprintf("cycle %d stalled: %s", w.cycleCount, detail))
+	if s.activeTrial != nil && s.activeTrial.trialID == w.trialID {
+		s.activeTrial.fault = w.tripped
+		s.activeTrial.stop()
+	}
+}
+
+// finishWatchLocked turns a cycle that ended in a watchdog trip or a panic
+// into a faulted result and records it. It returns nil when the cycle ended
+// on its own.
+func (s *kettleCycleTestController) finishWatchLocked(w *cycleWatch, timing cycleTiming, recovered interface{}) map[string]interface{} {
+	phase := s.currentPhaseLocked()
+	var result map[string]interface{}
+	switch {
+	case recovered != nil:
+		s.logger.Errorf("cycle %d panicked in %s: %v\n%s", w.cycleCount, phase, recovered, debug.Stack())
+		result = map[string]interface{}{"status": "completed", "phase": phase}
+		s.faultCycleLocked(result, faultCyclePanic, fmt.Sprintf("panic in %s: %v", phase, recovered))
+	case w.tripped != "":
+		result = map[string]interface{}{"status": "faulted", "fault": w.tripped, "phase": phase}
+	default:
+		return nil
+	}
+	s.recordCycleLocked(w.trialID, w.cycleCount, w.startedAt, time.Since(w.startedAt), timing, result)
+	return result
+}
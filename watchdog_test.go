@@ -0,0 +1,116 @@
+package kettlecycletest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestWatchdogConfig(t *testing.T) {
+	for _, cfg := range []WatchdogConfig{
+		{Multiple: 1},
+		{ExpectedMs: map[string]int{"coffee": 100}},
+		{ExpectedMs: map[string]int{phaseUpload: 0}},
+	} {
+		if err := cfg.validate(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+	if err := (&WatchdogConfig{ExpectedMs: map[string]int{watchdogCycle: 5000}}).validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+}
+
+func TestController_Watchdog(t *testing.T) {
+	newStallingController := func(t *testing.T, ignoreCancel bool) (*kettleCycleTestController, chan struct{}) {
+		kctrl := newTestController(t)
+		kctrl.cfg.Watchdog = &WatchdogConfig{
+			Multiple:   2,
+			ExpectedMs: map[string]int{phaseMoveToPourPrep: 50},
+			MinLimitMs: 1,
+		}
+		unblock := make(chan struct{})
+		pourPrep := inject.NewSwitch("pour-prep")
+		pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+			if ignoreCancel {
+				<-unblock
+				return nil
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		kctrl.pourPrep = pourPrep
+		kctrl.mu.Lock()
+		kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+		kctrl.mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go kctrl.runWatchdog(ctx)
+		return kctrl, unblock
+	}
+
+	t.Run("stalled phase is cancelled and faults the trial", func(t *testing.T) {
+		kctrl, _ := newStallingController(t, false)
+		result, err := kctrl.handleExecuteCycle(context.Background())
+		if err != nil {
+			t.Fatalf("expected a faulted result, got error %v", err)
+		}
+		fault := faultStalledPrefix + phaseMoveToPourPrep
+		if result["status"] != "faulted" || result["fault"] != fault {
+			t.Errorf("expected %s fault, got %v", fault, result)
+		}
+		state := kctrl.GetState()
+		if state["state"] != "faulted" || state["last_notification"] == "" {
+			t.Errorf("expected faulted trial with an alert, got %v", state)
+		}
+		history, _ := kctrl.DoCommand(context.Background(), map[string]interface{}{"command": "history"})
+		if cycles := history["cycles"].([]interface{}); len(cycles) != 1 {
+			t.Errorf("expected the stalled cycle in history, got %v", cycles)
+		}
+	})
+
+	t.Run("call ignoring cancellation still faults the trial", func(t *testing.T) {
+		kctrl, unblock := newStallingController(t, true)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			kctrl.handleExecuteCycle(context.Background())
+		}()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for kctrl.GetState()["state"] != "faulted" {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the trial to fault while the call is stuck")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		close(unblock)
+		<-done
+	})
+}
+
+func TestController_CyclePanic(t *testing.T) {
+	kctrl := newTestController(t)
+	pourPrep := inject.NewSwitch("pour-prep")
+	pourPrep.SetPositionFunc = func(ctx context.Context, position uint32, extra map[string]interface{}) error {
+		panic("driver bug")
+	}
+	kctrl.pourPrep = pourPrep
+	kctrl.mu.Lock()
+	kctrl.activeTrial = &trialState{trialID: "test-trial", stopCh: make(chan struct{})}
+	kctrl.mu.Unlock()
+
+	result, err := kctrl.handleExecuteCycle(context.Background())
+	if err != nil {
+		t.Fatalf("expected a faulted result, got error %v", err)
+	}
+	if result["fault"] != faultCyclePanic || result["phase"] != phaseMoveToPourPrep {
+		t.Errorf("expected cycle_panic in move_to_pour_prep, got %v", result)
+	}
+	if kctrl.GetState()["fault"] != faultCyclePanic {
+		t.Error("expected the trial faulted by the panic")
+	}
+}